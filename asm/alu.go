@@ -0,0 +1,53 @@
+package asm
+
+// aluOp is an ALU operation, the high 4 bits of an OpCode in the ALU64/ALU
+// classes.
+type aluOp uint8
+
+const (
+	addOp  aluOp = 0x00
+	subOp  aluOp = 0x01
+	mulOp  aluOp = 0x02
+	divOp  aluOp = 0x03
+	orOp   aluOp = 0x04
+	andOp  aluOp = 0x05
+	lshOp  aluOp = 0x06
+	rshOp  aluOp = 0x07
+	negOp  aluOp = 0x08
+	modOp  aluOp = 0x09
+	xorOp  aluOp = 0x0a
+	movOp  aluOp = 0x0b
+	arshOp aluOp = 0x0c
+)
+
+var (
+	Add = aluOp(addOp)
+	Sub = aluOp(subOp)
+	Mul = aluOp(mulOp)
+	Div = aluOp(divOp)
+	Or  = aluOp(orOp)
+	And = aluOp(andOp)
+	Lsh = aluOp(lshOp)
+	Rsh = aluOp(rshOp)
+	Mod = aluOp(modOp)
+	Xor = aluOp(xorOp)
+	Mov = aluOp(movOp)
+)
+
+// Reg returns an instruction computing dst = dst <op> src using 64-bit ALU.
+func (a aluOp) Reg(dst, src Register) Instruction {
+	return Instruction{
+		OpCode: Alu64Class.op(uint8(a), RegSource),
+		Dst:    dst,
+		Src:    src,
+	}
+}
+
+// Imm returns an instruction computing dst = dst <op> imm using 64-bit ALU.
+func (a aluOp) Imm(dst Register, imm int32) Instruction {
+	return Instruction{
+		OpCode:   Alu64Class.op(uint8(a), ImmSource),
+		Dst:      dst,
+		Constant: int64(imm),
+	}
+}
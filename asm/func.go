@@ -0,0 +1,23 @@
+package asm
+
+// A subset of the stable BPF helper function ids, in the order they appear
+// in the kernel's include/uapi/linux/bpf.h. Extend as new helpers are needed.
+const (
+	FnUnspec BPFFunc = iota
+	FnMapLookupElem
+	FnMapUpdateElem
+	FnMapDeleteElem
+	FnProbeRead
+	FnKtimeGetNs
+	FnTracePrintk
+	FnGetCurrentPidTgid
+	FnGetCurrentUidGid
+	FnGetCurrentComm
+	FnGetCurrentTask
+	FnTailCall
+	FnGetSmpProcessorID
+	FnPerfEventOutput
+	FnMapPushElem
+	FnMapPopElem
+	FnMapPeekElem
+)
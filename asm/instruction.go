@@ -0,0 +1,123 @@
+package asm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// pseudoMapFD is the value the kernel expects in the source register of a
+// wide BPF_LD | BPF_DW | BPF_IMM instruction that loads a map file descriptor.
+const pseudoMapFD = 1
+
+// Instruction is a single eBPF instruction. Most instructions encode to one
+// 8-byte slot; a wide immediate load (see LoadMapPtr) encodes to two.
+type Instruction struct {
+	OpCode   OpCode
+	Dst      Register
+	Src      Register
+	Offset   int16
+	Constant int64
+
+	// Label, if set, marks this instruction as the target of jumps that
+	// reference it by name.
+	Label string
+
+	// Reference, if set, names the Label this jump instruction targets. Its
+	// Offset field is resolved by Instructions.Marshal.
+	Reference string
+}
+
+// LoadMapPtr returns an instruction loading the map identified by fd as a
+// pointer into dst, using the kernel's wide BPF_LD | BPF_DW | BPF_IMM
+// encoding and the BPF_PSEUDO_MAP_FD source marker.
+func LoadMapPtr(dst Register, fd int) Instruction {
+	return Instruction{
+		OpCode:   LdClass.loadStore(ModeImm, SizeDW),
+		Dst:      dst,
+		Src:      pseudoMapFD,
+		Constant: int64(fd),
+	}
+}
+
+// Instructions is a sequence of instructions that can be assembled into a
+// loadable eBPF program.
+type Instructions []Instruction
+
+// Marshal encodes insns as 8-byte little- or big-endian instruction slots
+// (16 bytes for a wide BPF_LD | BPF_DW | BPF_IMM load), resolving jump
+// Reference fields against matching Label fields along the way.
+func (insns Instructions) Marshal(w io.Writer, bo binary.ByteOrder) error {
+	offsets, err := insns.resolveLabels()
+	if err != nil {
+		return err
+	}
+
+	slot := 0
+	for i, insn := range insns {
+		if insn.OpCode.Class() == JmpClass && insn.Reference != "" {
+			target, ok := offsets[insn.Reference]
+			if !ok {
+				return fmt.Errorf("instruction %d: unknown label %q", i, insn.Reference)
+			}
+			insn.Offset = int16(target - slot - 1)
+		}
+
+		if err := marshalOne(w, bo, insn); err != nil {
+			return fmt.Errorf("instruction %d: %w", i, err)
+		}
+
+		slot++
+		if insn.OpCode.isWideLoad() {
+			slot++
+		}
+	}
+
+	return nil
+}
+
+// resolveLabels computes, for every labeled instruction, the slot offset
+// (counted in 8-byte instruction units) it occupies.
+func (insns Instructions) resolveLabels() (map[string]int, error) {
+	offsets := make(map[string]int)
+
+	slot := 0
+	for i, insn := range insns {
+		if insn.Label != "" {
+			if _, exists := offsets[insn.Label]; exists {
+				return nil, fmt.Errorf("instruction %d: duplicate label %q", i, insn.Label)
+			}
+			offsets[insn.Label] = slot
+		}
+
+		slot++
+		if insn.OpCode.isWideLoad() {
+			slot++
+		}
+	}
+
+	return offsets, nil
+}
+
+func marshalOne(w io.Writer, bo binary.ByteOrder, insn Instruction) error {
+	regs := byte(insn.Dst&0x0f) | byte(insn.Src&0x0f)<<4
+
+	buf := make([]byte, 8)
+	buf[0] = byte(insn.OpCode)
+	buf[1] = regs
+	bo.PutUint16(buf[2:4], uint16(insn.Offset))
+	bo.PutUint32(buf[4:8], uint32(insn.Constant))
+
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+
+	if !insn.OpCode.isWideLoad() {
+		return nil
+	}
+
+	hi := make([]byte, 8)
+	bo.PutUint32(hi[4:8], uint32(insn.Constant>>32))
+	_, err := w.Write(hi)
+	return err
+}
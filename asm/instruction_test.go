@@ -0,0 +1,80 @@
+package asm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestMarshalSingleInstruction(t *testing.T) {
+	insns := Instructions{
+		Mov.Imm(R0, 42),
+	}
+
+	var buf bytes.Buffer
+	if err := insns.Marshal(&buf, binary.LittleEndian); err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if buf.Len() != 8 {
+		t.Fatalf("got %d bytes, want 8", buf.Len())
+	}
+}
+
+func TestMarshalWideLoadTakesTwoSlots(t *testing.T) {
+	insns := Instructions{
+		LoadMapPtr(R1, 7),
+		Mov.Imm(R0, 0),
+	}
+
+	var buf bytes.Buffer
+	if err := insns.Marshal(&buf, binary.LittleEndian); err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if buf.Len() != 24 {
+		t.Fatalf("got %d bytes, want 24 (16 for the wide load + 8 for the mov)", buf.Len())
+	}
+}
+
+func TestMarshalResolvesForwardLabel(t *testing.T) {
+	insns := Instructions{
+		{OpCode: JmpClass.op(0, ImmSource), Reference: "end"},
+		Mov.Imm(R0, 1),
+		{OpCode: Mov.Imm(R0, 0).OpCode, Dst: R0, Label: "end"},
+	}
+
+	var buf bytes.Buffer
+	if err := insns.Marshal(&buf, binary.LittleEndian); err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	got := buf.Bytes()[2:4]
+	offset := int16(binary.LittleEndian.Uint16(got))
+	if offset != 1 {
+		t.Fatalf("jump offset = %d, want 1", offset)
+	}
+}
+
+func TestMarshalUnknownLabelErrors(t *testing.T) {
+	insns := Instructions{
+		{OpCode: JmpClass.op(0, ImmSource), Reference: "missing"},
+	}
+
+	var buf bytes.Buffer
+	if err := insns.Marshal(&buf, binary.LittleEndian); err == nil {
+		t.Fatal("expected an error for an unresolved label reference")
+	}
+}
+
+func TestMarshalDuplicateLabelErrors(t *testing.T) {
+	insns := Instructions{
+		{OpCode: Mov.Imm(R0, 0).OpCode, Label: "dup"},
+		{OpCode: Mov.Imm(R0, 1).OpCode, Label: "dup"},
+	}
+
+	var buf bytes.Buffer
+	if err := insns.Marshal(&buf, binary.LittleEndian); err == nil {
+		t.Fatal("expected an error for a duplicate label")
+	}
+}
@@ -0,0 +1,83 @@
+package asm
+
+// jmpOp is a jump condition, the high 4 bits of an OpCode in the JMP class.
+type jmpOp uint8
+
+const (
+	jaOp   jmpOp = 0x00
+	jeqOp  jmpOp = 0x01
+	jgtOp  jmpOp = 0x02
+	jgeOp  jmpOp = 0x03
+	jsetOp jmpOp = 0x04
+	jneOp  jmpOp = 0x05
+	jsgtOp jmpOp = 0x06
+	jsgeOp jmpOp = 0x07
+	callOp jmpOp = 0x08
+	exitOp jmpOp = 0x09
+	jltOp  jmpOp = 0x0a
+	jleOp  jmpOp = 0x0b
+	jsltOp jmpOp = 0x0c
+	jsleOp jmpOp = 0x0d
+)
+
+var (
+	JEq  = jmpOp(jeqOp)
+	JGT  = jmpOp(jgtOp)
+	JGE  = jmpOp(jgeOp)
+	JSet = jmpOp(jsetOp)
+	JNE  = jmpOp(jneOp)
+	JSGT = jmpOp(jsgtOp)
+	JSGE = jmpOp(jsgeOp)
+	JLT  = jmpOp(jltOp)
+	JLE  = jmpOp(jleOp)
+	JSLT = jmpOp(jsltOp)
+	JSLE = jmpOp(jsleOp)
+)
+
+// Imm returns a jump to label if dst <op> imm holds.
+func (j jmpOp) Imm(dst Register, imm int32, label string) Instruction {
+	return Instruction{
+		OpCode:    JmpClass.op(uint8(j), ImmSource),
+		Dst:       dst,
+		Constant:  int64(imm),
+		Reference: label,
+	}
+}
+
+// Reg returns a jump to label if dst <op> src holds.
+func (j jmpOp) Reg(dst, src Register, label string) Instruction {
+	return Instruction{
+		OpCode:    JmpClass.op(uint8(j), RegSource),
+		Dst:       dst,
+		Src:       src,
+		Reference: label,
+	}
+}
+
+// Ja returns an unconditional jump to label.
+func Ja(label string) Instruction {
+	return Instruction{
+		OpCode:    JmpClass.op(uint8(jaOp), ImmSource),
+		Reference: label,
+	}
+}
+
+// BPFFunc identifies a BPF helper function by its stable numeric id, as
+// passed to the BPF_CALL instruction.
+type BPFFunc int32
+
+// Call returns an instruction invoking the helper function fn.
+func Call(fn BPFFunc) Instruction {
+	return Instruction{
+		OpCode:   JmpClass.op(uint8(callOp), ImmSource),
+		Constant: int64(fn),
+	}
+}
+
+// Return returns an instruction exiting the program, with the return value
+// taken from R0.
+func Return() Instruction {
+	return Instruction{
+		OpCode: JmpClass.op(uint8(exitOp), ImmSource),
+	}
+}
@@ -0,0 +1,70 @@
+package asm
+
+// OpCode is the first byte of an encoded eBPF instruction. Its low 3 bits
+// select the instruction class; the remaining bits are class-specific, as
+// defined by the kernel's BPF instruction set (Documentation/bpf/instruction-set.rst).
+type OpCode uint8
+
+// Class is the instruction class, the low 3 bits of an OpCode.
+type Class uint8
+
+const classMask OpCode = 0x07
+
+const (
+	LdClass    Class = 0x00
+	LdXClass   Class = 0x01
+	StClass    Class = 0x02
+	StXClass   Class = 0x03
+	AluClass   Class = 0x04
+	JmpClass   Class = 0x05
+	Jmp32Class Class = 0x06
+	Alu64Class Class = 0x07
+)
+
+// Source selects whether an ALU/JMP instruction's second operand is an
+// immediate or another register; it occupies bit 3 of the OpCode.
+type Source uint8
+
+const (
+	ImmSource Source = 0x00
+	RegSource Source = 0x08
+)
+
+// Size is the width of a memory access for load/store instructions.
+type Size uint8
+
+const (
+	SizeW  Size = 0x00 << 3
+	SizeH  Size = 0x01 << 3
+	SizeB  Size = 0x02 << 3
+	SizeDW Size = 0x03 << 3
+)
+
+// Mode is the addressing mode for load/store instructions.
+type Mode uint8
+
+const (
+	ModeImm Mode = 0x00 << 5
+	ModeAbs Mode = 0x01 << 5
+	ModeInd Mode = 0x02 << 5
+	ModeMem Mode = 0x03 << 5
+)
+
+func (c Class) op(op uint8, source Source) OpCode {
+	return OpCode(uint8(c)|uint8(source)) | OpCode(op)<<4
+}
+
+func (c Class) loadStore(mode Mode, size Size) OpCode {
+	return OpCode(c) | OpCode(mode) | OpCode(size)
+}
+
+// Class returns the instruction class encoded in op.
+func (op OpCode) Class() Class {
+	return Class(op & classMask)
+}
+
+// isWideLoad reports whether op is a BPF_LD | BPF_DW | BPF_IMM instruction,
+// the only one that occupies two consecutive 8-byte instruction slots.
+func (op OpCode) isWideLoad() bool {
+	return op.Class() == LdClass && Mode(op&0x60) == ModeImm && Size(op&0x18) == SizeDW
+}
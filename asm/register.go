@@ -0,0 +1,28 @@
+package asm
+
+// Register is one of the eBPF virtual machine's eleven 64-bit registers.
+type Register uint8
+
+const (
+	R0 Register = iota
+	R1
+	R2
+	R3
+	R4
+	R5
+	R6
+	R7
+	R8
+	R9
+	R10
+)
+
+// RFP is the read-only frame pointer, an alias for R10.
+const RFP = R10
+
+func (r Register) String() string {
+	if r == R10 {
+		return "rfp"
+	}
+	return "r" + string(rune('0'+r))
+}
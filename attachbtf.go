@@ -0,0 +1,44 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"fmt"
+
+	"github.com/khulnasoft-lab/libbpfgo/btf"
+)
+
+// SetAttachBTF resolves funcName against spec, rather than a raw,
+// architecture-dependent symbol string, validating that it names a function
+// before handing it to SetAttachTarget. This replaces patterns like:
+//
+//	funcName := fmt.Sprintf("__%s_sys_mmap", ksymArch())
+//
+// with a single funcName (e.g. "sys_mmap") resolved against the kernel's own
+// BTF, so selftests no longer need architecture-specific symbol juggling.
+//
+// Note that SetAttachTarget itself always errors for a BPFProg built via
+// LoadProgramFromInstructions: the kernel only accepts an attach target at
+// BPF_PROG_LOAD time, via ProgLoadOpts.AttachBTFID, not after the fact. This
+// still validates funcName against spec so callers get that feedback before
+// hitting the later SetAttachTarget error.
+func (p *BPFProg) SetAttachBTF(spec *btf.Spec, funcName string) error {
+	t, err := spec.TypeByName(funcName)
+	if err != nil {
+		return fmt.Errorf("resolving attach target %q: %w", funcName, err)
+	}
+
+	if _, ok := t.(*btf.Func); !ok {
+		return fmt.Errorf("attach target %q is a %T, not a function", funcName, t)
+	}
+
+	// A target fd of 0 means "resolve funcName against the BTF of the type
+	// this program is already associated with" (vmlinux for most tracing
+	// programs); spec having resolved funcName successfully is what lets
+	// callers skip building an architecture-specific symbol name themselves.
+	return p.SetAttachTarget(0, funcName)
+}
@@ -0,0 +1,65 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// BPFBloomFilter is a typed wrapper around a BPF_MAP_TYPE_BLOOM_FILTER map.
+// Bloom filter maps have no keys: BPF_MAP_UPDATE_ELEM adds a value with a nil
+// key, and BPF_MAP_LOOKUP_ELEM reports whether a value is (probably) present.
+type BPFBloomFilter struct {
+	bpfMap *BPFMap
+}
+
+// NewBPFBloomFilter wraps bpfMap, which must be a BPF_MAP_TYPE_BLOOM_FILTER
+// map created with a map_extra of 1-15 hash functions, as required by the
+// kernel.
+func NewBPFBloomFilter(bpfMap *BPFMap) (*BPFBloomFilter, error) {
+	if bpfMap.Type() != MapTypeBloomFilter {
+		return nil, fmt.Errorf("map %s is not a BPF_MAP_TYPE_BLOOM_FILTER map", bpfMap.Name())
+	}
+
+	info, err := GetMapInfoByFD(bpfMap.FD())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get info for map %s: %w", bpfMap.Name(), err)
+	}
+	if info.MapExtra < 1 || info.MapExtra > 15 {
+		return nil, fmt.Errorf("map %s has an invalid number of hash functions (map_extra=%d), want 1-15",
+			bpfMap.Name(), info.MapExtra)
+	}
+
+	return &BPFBloomFilter{bpfMap: bpfMap}, nil
+}
+
+// Add inserts value into the bloom filter.
+func (b *BPFBloomFilter) Add(value []byte) error {
+	retC := C.bpf_map_update_elem(C.int(b.bpfMap.FD()), nil, unsafe.Pointer(&value[0]), 0)
+	if retC < 0 {
+		return newError(fmt.Sprintf("BPFBloomFilter.Add(%s)", b.bpfMap.Name()), syscall.Errno(-retC))
+	}
+	return nil
+}
+
+// MayContain reports whether value is possibly a member of the bloom filter.
+// A false result means value is definitely not present; a true result may be
+// a false positive.
+func (b *BPFBloomFilter) MayContain(value []byte) (bool, error) {
+	retC := C.bpf_map_lookup_elem(C.int(b.bpfMap.FD()), nil, unsafe.Pointer(&value[0]))
+	if retC < 0 {
+		err := newError(fmt.Sprintf("BPFBloomFilter.MayContain(%s)", b.bpfMap.Name()), syscall.Errno(-retC))
+		if errors.Is(err, ErrKeyNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,21 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"syscall"
+)
+
+// GetBTFFDByID returns a file descriptor for the BTF object identified by id,
+// obtained via the BPF_BTF_GET_FD_BY_ID bpf(2) command.
+func GetBTFFDByID(id uint32) (int, error) {
+	fdC := C.bpf_btf_get_fd_by_id(C.uint(id))
+	if fdC < 0 {
+		return -1, newError("GetBTFFDByID", syscall.Errno(-fdC))
+	}
+	return int(fdC), nil
+}
@@ -0,0 +1,7 @@
+// Package btf parses BPF Type Format (BTF) data: the .BTF ELF section
+// embedded in a compiled BPF object, and the kernel's own type information
+// exposed under /sys/kernel/btf. It gives libbpfgo callers structured access
+// to struct/union/enum/function layouts instead of string-matching symbol
+// names (e.g. the __x64_sys_mmap vs __arm64_sys_mmap juggling that
+// architecture-specific kprobe/fentry targets otherwise require).
+package btf
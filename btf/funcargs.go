@@ -0,0 +1,19 @@
+package btf
+
+import "fmt"
+
+// ArgTypes returns the parameter types of fn, derived from its FuncProto.
+// It is the building block for auto-deriving fentry/fexit argument types
+// instead of hand-declaring a matching struct per traced function.
+func ArgTypes(fn *Func) ([]Type, error) {
+	proto, ok := fn.Type.(*FuncProto)
+	if !ok {
+		return nil, fmt.Errorf("func %q has no FuncProto signature", fn.TypeName())
+	}
+
+	types := make([]Type, len(proto.Params))
+	for i, p := range proto.Params {
+		types[i] = p.Type
+	}
+	return types, nil
+}
@@ -0,0 +1,36 @@
+package btf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const btfMagic = 0xeB9F
+
+// rawHeader mirrors struct btf_header from include/uapi/linux/btf.h.
+type rawHeader struct {
+	Magic   uint16
+	Version uint8
+	Flags   uint8
+	HdrLen  uint32
+
+	// Type section.
+	TypeOff uint32
+	TypeLen uint32
+
+	// String section.
+	StrOff uint32
+	StrLen uint32
+}
+
+func parseHeader(r io.Reader, bo binary.ByteOrder) (*rawHeader, error) {
+	var hdr rawHeader
+	if err := binary.Read(r, bo, &hdr); err != nil {
+		return nil, fmt.Errorf("reading BTF header: %w", err)
+	}
+	if hdr.Magic != btfMagic {
+		return nil, fmt.Errorf("invalid BTF magic %#x", hdr.Magic)
+	}
+	return &hdr, nil
+}
@@ -0,0 +1,267 @@
+package btf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Marshal encodes s back into the raw BTF binary format, for embedding a
+// .BTF section alongside a program loaded from raw instructions (see
+// libbpfgo.LoadProgramFromInstructions). The encoding is always little-endian,
+// matching how libbpf itself marshals generated BTF.
+func (s *Spec) Marshal() ([]byte, error) {
+	var typeBuf, strBuf bytes.Buffer
+	strBuf.WriteByte(0) // offset 0 is always the empty string
+
+	strOff := make(map[string]uint32)
+	internString := func(name string) uint32 {
+		if name == "" {
+			return 0
+		}
+		if off, ok := strOff[name]; ok {
+			return off
+		}
+		off := uint32(strBuf.Len())
+		strBuf.WriteString(name)
+		strBuf.WriteByte(0)
+		strOff[name] = off
+		return off
+	}
+
+	for _, t := range s.types[1:] {
+		if err := marshalType(&typeBuf, t, internString); err != nil {
+			return nil, fmt.Errorf("marshaling %s %q: %w", kindOf(t), t.TypeName(), err)
+		}
+	}
+
+	hdr := rawHeader{
+		Magic:   btfMagic,
+		Version: 1,
+		HdrLen:  24, // sizeof(rawHeader)
+		TypeOff: 0,
+		TypeLen: uint32(typeBuf.Len()),
+		StrOff:  uint32(typeBuf.Len()),
+		StrLen:  uint32(strBuf.Len()),
+	}
+
+	var out bytes.Buffer
+	if err := binary.Write(&out, binary.LittleEndian, hdr); err != nil {
+		return nil, err
+	}
+	out.Write(typeBuf.Bytes())
+	out.Write(strBuf.Bytes())
+
+	return out.Bytes(), nil
+}
+
+func kindOf(t Type) Kind {
+	switch t.(type) {
+	case *Int:
+		return KindInt
+	case *Pointer:
+		return KindPointer
+	case *Array:
+		return KindArray
+	case *Struct:
+		return KindStruct
+	case *Union:
+		return KindUnion
+	case *Enum:
+		return KindEnum
+	case *Fwd:
+		return KindForward
+	case *Typedef:
+		return KindTypedef
+	case *Volatile:
+		return KindVolatile
+	case *Const:
+		return KindConst
+	case *Restrict:
+		return KindRestrict
+	case *Func:
+		return KindFunc
+	case *FuncProto:
+		return KindFuncProto
+	case *Var:
+		return KindVar
+	case *Datasec:
+		return KindDatasec
+	case *Float:
+		return KindFloat
+	case *DeclTag:
+		return KindDeclTag
+	case *TypeTag:
+		return KindTypeTag
+	default:
+		return KindUnknown
+	}
+}
+
+func marshalType(buf *bytes.Buffer, t Type, internString func(string) uint32) error {
+	kind := kindOf(t)
+
+	var info uint32 = uint32(kind) << 24
+	var sizeOrType uint32
+	kindFlag := false
+	var vlen int
+
+	switch v := t.(type) {
+	case *Int:
+		sizeOrType = v.Size
+	case *Pointer:
+		sizeOrType = uint32(v.Target.typeID())
+	case *Array:
+		// Array's size/type union is unused; its trailer carries the ids.
+	case *Struct:
+		sizeOrType = v.Size
+		vlen = len(v.Members)
+		kindFlag = anyBitfieldMember(v.Members)
+	case *Union:
+		sizeOrType = v.Size
+		vlen = len(v.Members)
+		kindFlag = anyBitfieldMember(v.Members)
+	case *Enum:
+		sizeOrType = v.Size
+		vlen = len(v.Values)
+	case *Fwd:
+		kindFlag = v.Kind == FwdUnion
+	case *Typedef:
+		sizeOrType = uint32(v.Target.typeID())
+	case *Volatile:
+		sizeOrType = uint32(v.Target.typeID())
+	case *Const:
+		sizeOrType = uint32(v.Target.typeID())
+	case *Restrict:
+		sizeOrType = uint32(v.Target.typeID())
+	case *Func:
+		sizeOrType = uint32(v.Type.typeID())
+		vlen = int(v.Linkage)
+	case *FuncProto:
+		sizeOrType = uint32(v.Return.typeID())
+		vlen = len(v.Params)
+	case *Var:
+		sizeOrType = uint32(v.Type.typeID())
+	case *Datasec:
+		sizeOrType = v.Size
+		vlen = len(v.Vars)
+	case *Float:
+		sizeOrType = v.Size
+	case *DeclTag:
+		sizeOrType = uint32(v.Target.typeID())
+	case *TypeTag:
+		sizeOrType = uint32(v.Target.typeID())
+	default:
+		return fmt.Errorf("unhandled type %T", t)
+	}
+
+	info |= uint32(vlen) & 0xffff
+	if kindFlag {
+		info |= 1 << 31
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, internString(t.TypeName())); err != nil {
+		return err
+	}
+	if err := binary.Write(buf, binary.LittleEndian, info); err != nil {
+		return err
+	}
+	if err := binary.Write(buf, binary.LittleEndian, sizeOrType); err != nil {
+		return err
+	}
+
+	return marshalTrailer(buf, t, internString)
+}
+
+func marshalTrailer(buf *bytes.Buffer, t Type, internString func(string) uint32) error {
+	switch v := t.(type) {
+	case *Int:
+		info := uint32(v.Bits) | uint32(v.Offset)<<8 | uint32(v.Encoding)<<24
+		return binary.Write(buf, binary.LittleEndian, info)
+
+	case *Array:
+		return binary.Write(buf, binary.LittleEndian, rawArray{
+			ElemType:  uint32(v.Element.typeID()),
+			IndexType: uint32(v.Index.typeID()),
+			Nelems:    v.Nelems,
+		})
+
+	case *Struct:
+		return marshalMembers(buf, v.Members, internString)
+	case *Union:
+		return marshalMembers(buf, v.Members, internString)
+
+	case *Enum:
+		for _, e := range v.Values {
+			if err := binary.Write(buf, binary.LittleEndian, rawEnum{
+				NameOff: internString(e.Name),
+				Val:     int32(e.Value),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *FuncProto:
+		for _, p := range v.Params {
+			if err := binary.Write(buf, binary.LittleEndian, rawParam{
+				NameOff: internString(p.Name),
+				Type:    uint32(p.Type.typeID()),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *Datasec:
+		for _, s := range v.Vars {
+			if err := binary.Write(buf, binary.LittleEndian, rawSecinfo{
+				Type:   uint32(s.Type.typeID()),
+				Offset: s.Offset,
+				Size:   s.Size,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *DeclTag:
+		return binary.Write(buf, binary.LittleEndian, v.ComponentIdx)
+
+	case *Var:
+		return binary.Write(buf, binary.LittleEndian, uint32(v.Linkage))
+
+	default:
+		return nil
+	}
+}
+
+// anyBitfieldMember reports whether any member of a struct/union is a
+// bitfield, which determines whether the type must be marshaled with
+// kind_flag set so that its members' packed Offset (bit_offset |
+// bitfield_size<<24) is decoded correctly by fixupMembers on read-back.
+func anyBitfieldMember(members []Member) bool {
+	for _, m := range members {
+		if m.BitfieldSize != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func marshalMembers(buf *bytes.Buffer, members []Member, internString func(string) uint32) error {
+	for _, m := range members {
+		offset := m.OffsetBits
+		if m.BitfieldSize != 0 {
+			offset |= uint32(m.BitfieldSize) << 24
+		}
+		if err := binary.Write(buf, binary.LittleEndian, rawMember{
+			NameOff: internString(m.Name),
+			Type:    uint32(m.Type.typeID()),
+			Offset:  offset,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package btf
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	u32 := &Int{base: base{id: 1, Name: "unsigned int"}, Size: 4, Bits: 32, Encoding: IntPlain}
+	ptr := &Pointer{base: base{id: 2}, Target: u32}
+	s := &Struct{
+		base: base{id: 3, Name: "foo"},
+		Size: 8,
+		Members: []Member{
+			{Name: "a", Type: u32, OffsetBits: 0},
+			{Name: "b", Type: ptr, OffsetBits: 32},
+		},
+	}
+
+	spec := &Spec{
+		types: []Type{&Void{}, u32, ptr, s},
+		byName: map[string][]Type{
+			"unsigned int": {u32},
+			"foo":          {s},
+		},
+	}
+
+	data, err := spec.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	got, err := LoadSpecFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("LoadSpecFromReader: %v", err)
+	}
+
+	gotStruct, err := got.TypeByName("foo")
+	if err != nil {
+		t.Fatalf("TypeByName(foo): %v", err)
+	}
+	fooStruct, ok := gotStruct.(*Struct)
+	if !ok {
+		t.Fatalf("TypeByName(foo) = %T, want *Struct", gotStruct)
+	}
+	if fooStruct.Size != 8 {
+		t.Errorf("foo.Size = %d, want 8", fooStruct.Size)
+	}
+	if len(fooStruct.Members) != 2 {
+		t.Fatalf("foo has %d members, want 2", len(fooStruct.Members))
+	}
+	if fooStruct.Members[0].Name != "a" || fooStruct.Members[1].Name != "b" {
+		t.Errorf("foo members = %+v", fooStruct.Members)
+	}
+
+	gotPtrTarget, ok := fooStruct.Members[1].Type.(*Pointer)
+	if !ok {
+		t.Fatalf("member b type = %T, want *Pointer", fooStruct.Members[1].Type)
+	}
+	gotInt, ok := gotPtrTarget.Target.(*Int)
+	if !ok {
+		t.Fatalf("pointer target = %T, want *Int", gotPtrTarget.Target)
+	}
+	if gotInt.TypeName() != "unsigned int" || gotInt.Size != 4 {
+		t.Errorf("pointer target = %+v", gotInt)
+	}
+}
+
+func TestMarshalRoundTripBitfieldMember(t *testing.T) {
+	u32 := &Int{base: base{id: 1, Name: "unsigned int"}, Size: 4, Bits: 32, Encoding: IntPlain}
+	s := &Struct{
+		base: base{id: 2, Name: "bits"},
+		Size: 4,
+		Members: []Member{
+			{Name: "a", Type: u32, OffsetBits: 0, BitfieldSize: 4},
+			{Name: "b", Type: u32, OffsetBits: 4, BitfieldSize: 12},
+		},
+	}
+
+	spec := &Spec{
+		types: []Type{&Void{}, u32, s},
+		byName: map[string][]Type{
+			"unsigned int": {u32},
+			"bits":         {s},
+		},
+	}
+
+	data, err := spec.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	got, err := LoadSpecFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("LoadSpecFromReader: %v", err)
+	}
+
+	gotStruct, err := got.TypeByName("bits")
+	if err != nil {
+		t.Fatalf("TypeByName(bits): %v", err)
+	}
+	bitsStruct, ok := gotStruct.(*Struct)
+	if !ok {
+		t.Fatalf("TypeByName(bits) = %T, want *Struct", gotStruct)
+	}
+	if len(bitsStruct.Members) != 2 {
+		t.Fatalf("bits has %d members, want 2", len(bitsStruct.Members))
+	}
+	if bitsStruct.Members[0].OffsetBits != 0 || bitsStruct.Members[0].BitfieldSize != 4 {
+		t.Errorf("member a = %+v, want OffsetBits=0 BitfieldSize=4", bitsStruct.Members[0])
+	}
+	if bitsStruct.Members[1].OffsetBits != 4 || bitsStruct.Members[1].BitfieldSize != 12 {
+		t.Errorf("member b = %+v, want OffsetBits=4 BitfieldSize=12", bitsStruct.Members[1])
+	}
+}
+
+func TestLoadSpecFromReaderRejectsBadMagic(t *testing.T) {
+	if _, err := LoadSpecFromReader(bytes.NewReader([]byte{0, 0, 0, 0})); err == nil {
+		t.Fatal("expected an error for data with no valid BTF magic")
+	}
+}
+
+func TestTypeByIDOutOfRange(t *testing.T) {
+	spec := &Spec{types: []Type{&Void{}}}
+	if _, err := spec.TypeByID(5); err == nil {
+		t.Fatal("expected an error for an out-of-range type id")
+	}
+}
+
+func TestWalkVisitsEachTypeOnce(t *testing.T) {
+	u32 := &Int{base: base{id: 1, Name: "u32"}, Size: 4}
+	s := &Struct{
+		base: base{id: 2, Name: "self"},
+	}
+	// A struct containing a pointer to itself, to exercise cycle handling.
+	ptrToSelf := &Pointer{base: base{id: 3}, Target: s}
+	s.Members = []Member{
+		{Name: "n", Type: u32},
+		{Name: "next", Type: ptrToSelf},
+	}
+
+	var visited []TypeID
+	err := Walk(s, func(t Type) error {
+		visited = append(visited, t.typeID())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+
+	if len(visited) != 3 {
+		t.Fatalf("visited %d types, want 3 (got %v)", len(visited), visited)
+	}
+}
@@ -0,0 +1,127 @@
+package btf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// rawBTFType mirrors struct btf_type from include/uapi/linux/btf.h, plus
+// whatever kind-specific trailer followed it in the type section.
+type rawBTFType struct {
+	NameOff  uint32
+	Kind     Kind
+	KindFlag bool
+	Vlen     int
+
+	// SizeOrType is the btf_type.size/type union: a byte size for
+	// int/enum/struct/union/datasec/float, or a referenced TypeID otherwise.
+	SizeOrType uint32
+
+	// Kind-specific trailers, only one of which is populated.
+	IntInfo    uint32
+	Array      rawArray
+	Members    []rawMember
+	Enums      []rawEnum
+	Enums64    []rawEnum64
+	Params     []rawParam
+	Secinfos   []rawSecinfo
+	Linkage    uint32
+	DeclTagIdx int32
+}
+
+type rawArray struct {
+	ElemType, IndexType, Nelems uint32
+}
+
+type rawMember struct {
+	NameOff, Type, Offset uint32
+}
+
+type rawEnum struct {
+	NameOff uint32
+	Val     int32
+}
+
+type rawEnum64 struct {
+	NameOff          uint32
+	ValLo32, ValHi32 uint32
+}
+
+type rawParam struct {
+	NameOff, Type uint32
+}
+
+type rawSecinfo struct {
+	Type, Offset, Size uint32
+}
+
+// parseRawTypes decodes every btf_type record in the type section into
+// rawBTFType, preserving BTF's 1-based type numbering (index 0 is reserved
+// for the implicit "void" type).
+func parseRawTypes(r io.Reader, bo binary.ByteOrder) ([]rawBTFType, error) {
+	raws := []rawBTFType{{Kind: KindUnknown}} // id 0 == void
+
+	for {
+		var nameOff, info, sizeOrType uint32
+		if err := binary.Read(r, bo, &nameOff); err != nil {
+			if err == io.EOF {
+				return raws, nil
+			}
+			return nil, err
+		}
+		if err := binary.Read(r, bo, &info); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, bo, &sizeOrType); err != nil {
+			return nil, err
+		}
+
+		raw := rawBTFType{
+			NameOff:    nameOff,
+			Kind:       Kind((info >> 24) & 0x1f),
+			KindFlag:   info&(1<<31) != 0,
+			Vlen:       int(info & 0xffff),
+			SizeOrType: sizeOrType,
+		}
+
+		if err := readTrailer(r, bo, &raw); err != nil {
+			return nil, fmt.Errorf("type %d (%s): %w", len(raws), raw.Kind, err)
+		}
+
+		raws = append(raws, raw)
+	}
+}
+
+func readTrailer(r io.Reader, bo binary.ByteOrder, raw *rawBTFType) error {
+	switch raw.Kind {
+	case KindInt:
+		return binary.Read(r, bo, &raw.IntInfo)
+	case KindArray:
+		return binary.Read(r, bo, &raw.Array)
+	case KindStruct, KindUnion:
+		raw.Members = make([]rawMember, raw.Vlen)
+		return binary.Read(r, bo, &raw.Members)
+	case KindEnum:
+		raw.Enums = make([]rawEnum, raw.Vlen)
+		return binary.Read(r, bo, &raw.Enums)
+	case KindEnum64:
+		raw.Enums64 = make([]rawEnum64, raw.Vlen)
+		return binary.Read(r, bo, &raw.Enums64)
+	case KindFuncProto:
+		raw.Params = make([]rawParam, raw.Vlen)
+		return binary.Read(r, bo, &raw.Params)
+	case KindVar:
+		return binary.Read(r, bo, &raw.Linkage)
+	case KindDatasec:
+		raw.Secinfos = make([]rawSecinfo, raw.Vlen)
+		return binary.Read(r, bo, &raw.Secinfos)
+	case KindDeclTag:
+		return binary.Read(r, bo, &raw.DeclTagIdx)
+	default:
+		// KindPointer, KindTypedef, KindVolatile, KindConst, KindRestrict,
+		// KindFunc, KindForward, KindFloat, KindTypeTag and KindUnknown carry
+		// no trailer beyond the base btf_type record.
+		return nil
+	}
+}
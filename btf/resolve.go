@@ -0,0 +1,337 @@
+package btf
+
+import "fmt"
+
+// resolve turns raw (id-addressed) type records into a graph of Type values.
+// It proceeds in two passes so that forward and cyclic references (e.g. a
+// struct containing a pointer to itself) resolve correctly: the first pass
+// allocates one concrete, addressable Type per id; the second fills in every
+// field that references another type by id, by which point every id already
+// has a live Type to point to.
+func resolve(raws []rawBTFType, strData []byte) (*Spec, error) {
+	types := make([]Type, len(raws))
+	types[0] = &Void{}
+
+	for id := 1; id < len(raws); id++ {
+		raw := raws[id]
+		name := str(strData, raw.NameOff)
+		b := base{id: TypeID(id), Name: name}
+
+		switch raw.Kind {
+		case KindInt:
+			types[id] = &Int{base: b}
+		case KindPointer:
+			types[id] = &Pointer{base: b}
+		case KindArray:
+			types[id] = &Array{base: b}
+		case KindStruct:
+			types[id] = &Struct{base: b}
+		case KindUnion:
+			types[id] = &Union{base: b}
+		case KindEnum, KindEnum64:
+			types[id] = &Enum{base: b}
+		case KindForward:
+			types[id] = &Fwd{base: b}
+		case KindTypedef:
+			types[id] = &Typedef{base: b}
+		case KindVolatile:
+			types[id] = &Volatile{base: b}
+		case KindConst:
+			types[id] = &Const{base: b}
+		case KindRestrict:
+			types[id] = &Restrict{base: b}
+		case KindFunc:
+			types[id] = &Func{base: b}
+		case KindFuncProto:
+			types[id] = &FuncProto{base: b}
+		case KindVar:
+			types[id] = &Var{base: b}
+		case KindDatasec:
+			types[id] = &Datasec{base: b}
+		case KindFloat:
+			types[id] = &Float{base: b}
+		case KindDeclTag:
+			types[id] = &DeclTag{base: b}
+		case KindTypeTag:
+			types[id] = &TypeTag{base: b}
+		default:
+			return nil, fmt.Errorf("type %d: unsupported BTF kind %s", id, raw.Kind)
+		}
+	}
+
+	byName := make(map[string][]Type)
+	typeAt := func(id uint32) (Type, error) {
+		if int(id) >= len(types) {
+			return nil, fmt.Errorf("type id %d out of range", id)
+		}
+		return types[id], nil
+	}
+
+	for id := 1; id < len(raws); id++ {
+		raw := raws[id]
+		if err := fixup(types[id], raw, strData, typeAt); err != nil {
+			return nil, fmt.Errorf("type %d (%s): %w", id, raw.Kind, err)
+		}
+		if name := types[id].TypeName(); name != "" {
+			byName[name] = append(byName[name], types[id])
+		}
+	}
+
+	return &Spec{types: types, byName: byName}, nil
+}
+
+func fixup(t Type, raw rawBTFType, strData []byte, typeAt func(uint32) (Type, error)) error {
+	switch v := t.(type) {
+	case *Int:
+		v.Size = raw.SizeOrType
+		v.Bits = uint8(raw.IntInfo & 0xff)
+		v.Offset = uint8((raw.IntInfo >> 8) & 0xff)
+		v.Encoding = IntEncoding((raw.IntInfo >> 24) & 0x0f)
+
+	case *Pointer:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Target = target
+
+	case *Array:
+		elem, err := typeAt(raw.Array.ElemType)
+		if err != nil {
+			return err
+		}
+		index, err := typeAt(raw.Array.IndexType)
+		if err != nil {
+			return err
+		}
+		v.Element = elem
+		v.Index = index
+		v.Nelems = raw.Array.Nelems
+
+	case *Struct:
+		v.Size = raw.SizeOrType
+		members, err := fixupMembers(raw, strData, typeAt)
+		if err != nil {
+			return err
+		}
+		v.Members = members
+
+	case *Union:
+		v.Size = raw.SizeOrType
+		members, err := fixupMembers(raw, strData, typeAt)
+		if err != nil {
+			return err
+		}
+		v.Members = members
+
+	case *Enum:
+		v.Size = raw.SizeOrType
+		if raw.Kind == KindEnum64 {
+			for _, e := range raw.Enums64 {
+				val := int64(e.ValLo32) | int64(e.ValHi32)<<32
+				v.Values = append(v.Values, EnumValue{Name: str(strData, e.NameOff), Value: val})
+			}
+		} else {
+			v.Signed = true
+			for _, e := range raw.Enums {
+				v.Values = append(v.Values, EnumValue{Name: str(strData, e.NameOff), Value: int64(e.Val)})
+			}
+		}
+
+	case *Fwd:
+		if raw.KindFlag {
+			v.Kind = FwdUnion
+		} else {
+			v.Kind = FwdStruct
+		}
+
+	case *Typedef:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Target = target
+
+	case *Volatile:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Target = target
+
+	case *Const:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Target = target
+
+	case *Restrict:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Target = target
+
+	case *Func:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Type = target
+		v.Linkage = FuncLinkage(raw.Vlen)
+
+	case *FuncProto:
+		ret, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Return = ret
+		for _, p := range raw.Params {
+			paramType, err := typeAt(p.Type)
+			if err != nil {
+				return err
+			}
+			v.Params = append(v.Params, Param{Name: str(strData, p.NameOff), Type: paramType})
+		}
+
+	case *Var:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Type = target
+		v.Linkage = FuncLinkage(raw.Linkage)
+
+	case *Datasec:
+		v.Size = raw.SizeOrType
+		for _, s := range raw.Secinfos {
+			secType, err := typeAt(s.Type)
+			if err != nil {
+				return err
+			}
+			v.Vars = append(v.Vars, VarSecinfo{Type: secType, Offset: s.Offset, Size: s.Size})
+		}
+
+	case *Float:
+		v.Size = raw.SizeOrType
+
+	case *DeclTag:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Target = target
+		v.ComponentIdx = raw.DeclTagIdx
+
+	case *TypeTag:
+		target, err := typeAt(raw.SizeOrType)
+		if err != nil {
+			return err
+		}
+		v.Target = target
+
+	default:
+		return fmt.Errorf("unhandled type %T", t)
+	}
+
+	return nil
+}
+
+func fixupMembers(raw rawBTFType, strData []byte, typeAt func(uint32) (Type, error)) ([]Member, error) {
+	members := make([]Member, 0, len(raw.Members))
+	for _, m := range raw.Members {
+		memberType, err := typeAt(m.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		member := Member{Name: str(strData, m.NameOff), Type: memberType}
+		if raw.KindFlag {
+			member.OffsetBits = m.Offset & 0xffffff
+			member.BitfieldSize = uint8(m.Offset >> 24)
+		} else {
+			member.OffsetBits = m.Offset
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// Walk visits root and every type reachable from it exactly once, calling
+// visit for each. It is safe for cyclic type graphs (e.g. a struct
+// containing a pointer to itself).
+func Walk(root Type, visit func(Type) error) error {
+	seen := make(map[TypeID]bool)
+	return walk(root, seen, visit)
+}
+
+func walk(t Type, seen map[TypeID]bool, visit func(Type) error) error {
+	if t == nil || seen[t.typeID()] {
+		return nil
+	}
+	seen[t.typeID()] = true
+
+	if err := visit(t); err != nil {
+		return err
+	}
+
+	for _, child := range children(t) {
+		if err := walk(child, seen, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func children(t Type) []Type {
+	switch v := t.(type) {
+	case *Pointer:
+		return []Type{v.Target}
+	case *Array:
+		return []Type{v.Element, v.Index}
+	case *Struct:
+		return memberTypes(v.Members)
+	case *Union:
+		return memberTypes(v.Members)
+	case *Typedef:
+		return []Type{v.Target}
+	case *Volatile:
+		return []Type{v.Target}
+	case *Const:
+		return []Type{v.Target}
+	case *Restrict:
+		return []Type{v.Target}
+	case *Func:
+		return []Type{v.Type}
+	case *FuncProto:
+		ts := make([]Type, 0, len(v.Params)+1)
+		ts = append(ts, v.Return)
+		for _, p := range v.Params {
+			ts = append(ts, p.Type)
+		}
+		return ts
+	case *Var:
+		return []Type{v.Type}
+	case *Datasec:
+		ts := make([]Type, 0, len(v.Vars))
+		for _, s := range v.Vars {
+			ts = append(ts, s.Type)
+		}
+		return ts
+	case *DeclTag:
+		return []Type{v.Target}
+	case *TypeTag:
+		return []Type{v.Target}
+	default:
+		return nil
+	}
+}
+
+func memberTypes(members []Member) []Type {
+	ts := make([]Type, len(members))
+	for i, m := range members {
+		ts[i] = m.Type
+	}
+	return ts
+}
@@ -0,0 +1,123 @@
+package btf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Spec is a parsed BTF type section: every Type it contains, indexed both by
+// TypeID and by name.
+type Spec struct {
+	types  []Type // types[id] is the Type for TypeID(id); types[0] is Void
+	byName map[string][]Type
+}
+
+// TypeByName returns the first type named name. If more than one type shares
+// a name (common for Struct/Union/Enum which live in their own namespaces),
+// callers that care about the distinction should filter the result with a
+// type assertion, or walk Spec.Types directly.
+func (s *Spec) TypeByName(name string) (Type, error) {
+	types, ok := s.byName[name]
+	if !ok || len(types) == 0 {
+		return nil, fmt.Errorf("type %q not found in BTF", name)
+	}
+	return types[0], nil
+}
+
+// Types returns every named type that exactly matches name, in declaration
+// order. It is useful when name is ambiguous between e.g. a struct and a
+// typedef of the same name.
+func (s *Spec) Types(name string) []Type {
+	return s.byName[name]
+}
+
+// TypeByID returns the type with the given id, or an error if id is out of
+// range.
+func (s *Spec) TypeByID(id TypeID) (Type, error) {
+	if int(id) >= len(s.types) {
+		return nil, fmt.Errorf("type id %d out of range", id)
+	}
+	return s.types[id], nil
+}
+
+// LoadSpecFromReader parses the raw BTF type data (as found in an ELF .BTF
+// section, or in /sys/kernel/btf/*) read from r.
+func LoadSpecFromReader(r io.Reader) (*Spec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading BTF data: %w", err)
+	}
+	return loadSpec(data)
+}
+
+// LoadKernelSpec parses the running kernel's own BTF, exposed at
+// /sys/kernel/btf/vmlinux since Linux 5.1.
+func LoadKernelSpec() (*Spec, error) {
+	return loadSpecFromFile("/sys/kernel/btf/vmlinux")
+}
+
+// LoadKernelModuleSpec parses the BTF for the loaded kernel module name,
+// exposed at /sys/kernel/btf/<name>. A module's BTF is a split BTF: its own
+// types reference the running kernel's vmlinux BTF by type id rather than
+// duplicating it, but this function does not merge against a vmlinux Spec,
+// so any such cross-references are left unresolved. Only types wholly
+// contained within the module's own BTF can be looked up on the result.
+func LoadKernelModuleSpec(name string) (*Spec, error) {
+	return loadSpecFromFile("/sys/kernel/btf/" + name)
+}
+
+func loadSpecFromFile(path string) (*Spec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+	return LoadSpecFromReader(f)
+}
+
+func loadSpec(data []byte) (*Spec, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("BTF data too short")
+	}
+
+	bo := byteOrder(data)
+
+	r := bytes.NewReader(data)
+	hdr, err := parseHeader(r, bo)
+	if err != nil {
+		return nil, err
+	}
+
+	typeData := data[hdr.HdrLen+hdr.TypeOff : hdr.HdrLen+hdr.TypeOff+hdr.TypeLen]
+	strData := data[hdr.HdrLen+hdr.StrOff : hdr.HdrLen+hdr.StrOff+hdr.StrLen]
+
+	raws, err := parseRawTypes(bytes.NewReader(typeData), bo)
+	if err != nil {
+		return nil, fmt.Errorf("parsing BTF types: %w", err)
+	}
+
+	return resolve(raws, strData)
+}
+
+// byteOrder sniffs the BTF header's magic number to determine whether the
+// data was produced for a little- or big-endian target.
+func byteOrder(data []byte) binary.ByteOrder {
+	if binary.LittleEndian.Uint16(data) == btfMagic {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+func str(strData []byte, off uint32) string {
+	if int(off) >= len(strData) {
+		return ""
+	}
+	end := bytes.IndexByte(strData[off:], 0)
+	if end < 0 {
+		return string(strData[off:])
+	}
+	return string(strData[off : int(off)+end])
+}
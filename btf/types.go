@@ -0,0 +1,275 @@
+package btf
+
+import "fmt"
+
+// Kind identifies the on-disk shape of a BTF type record.
+type Kind uint8
+
+const (
+	KindUnknown Kind = iota
+	KindInt
+	KindPointer
+	KindArray
+	KindStruct
+	KindUnion
+	KindEnum
+	KindForward
+	KindTypedef
+	KindVolatile
+	KindConst
+	KindRestrict
+	KindFunc
+	KindFuncProto
+	KindVar
+	KindDatasec
+	KindFloat
+	KindDeclTag
+	KindTypeTag
+	KindEnum64
+)
+
+// TypeID is a type's 1-based index into a Spec, as referenced by other
+// types' Type fields. TypeID 0 always means "void".
+type TypeID uint32
+
+// Type is implemented by every concrete BTF type. Name returns "" for types
+// that aren't named (pointers, arrays, qualifiers, ...).
+type Type interface {
+	TypeName() string
+	typeID() TypeID
+}
+
+type base struct {
+	id   TypeID
+	Name string
+}
+
+func (b *base) TypeName() string { return b.Name }
+func (b *base) typeID() TypeID   { return b.id }
+
+// Void is the implicit type referenced by TypeID 0.
+type Void struct{ base }
+
+// IntEncoding describes how an Int's bits should be interpreted.
+type IntEncoding uint8
+
+const (
+	IntPlain IntEncoding = iota
+	IntSigned
+	IntChar
+	IntBool
+)
+
+// Int is an integer type such as int, unsigned long, or _Bool.
+type Int struct {
+	base
+	Size     uint32
+	Bits     uint8
+	Offset   uint8
+	Encoding IntEncoding
+}
+
+// Pointer is a pointer to another type.
+type Pointer struct {
+	base
+	Target Type
+}
+
+// Array is a fixed-size array of Type elements.
+type Array struct {
+	base
+	Index   Type
+	Element Type
+	Nelems  uint32
+}
+
+// Member is a single field of a Struct or Union.
+type Member struct {
+	Name         string
+	Type         Type
+	OffsetBits   uint32
+	BitfieldSize uint8
+}
+
+// Struct is a BTF_KIND_STRUCT record.
+type Struct struct {
+	base
+	Size    uint32
+	Members []Member
+}
+
+// Union is a BTF_KIND_UNION record.
+type Union struct {
+	base
+	Size    uint32
+	Members []Member
+}
+
+// EnumValue is a single named constant of an Enum.
+type EnumValue struct {
+	Name  string
+	Value int64
+}
+
+// Enum is a BTF_KIND_ENUM or BTF_KIND_ENUM64 record.
+type Enum struct {
+	base
+	Size   uint32
+	Signed bool
+	Values []EnumValue
+}
+
+// FwdKind distinguishes a forward-declared struct from a forward-declared
+// union.
+type FwdKind uint8
+
+const (
+	FwdStruct FwdKind = iota
+	FwdUnion
+)
+
+// Fwd is a forward declaration of a Struct or Union.
+type Fwd struct {
+	base
+	Kind FwdKind
+}
+
+// Typedef is a named alias for another type.
+type Typedef struct {
+	base
+	Target Type
+}
+
+// Volatile, Const and Restrict are type qualifiers wrapping Target.
+type Volatile struct {
+	base
+	Target Type
+}
+
+type Const struct {
+	base
+	Target Type
+}
+
+type Restrict struct {
+	base
+	Target Type
+}
+
+// Param is a single argument of a FuncProto.
+type Param struct {
+	Name string
+	Type Type
+}
+
+// FuncProto is a function's signature: its parameter and return types.
+type FuncProto struct {
+	base
+	Return Type
+	Params []Param
+}
+
+// FuncLinkage describes a Func's visibility, mirroring the ELF symbol
+// binding of the function it was derived from.
+type FuncLinkage uint8
+
+const (
+	LinkageStatic FuncLinkage = iota
+	LinkageGlobal
+	LinkageExtern
+)
+
+// Func is a named function, whose signature is its referenced FuncProto.
+type Func struct {
+	base
+	Type    Type
+	Linkage FuncLinkage
+}
+
+// Var is a named variable, such as a global.
+type Var struct {
+	base
+	Type    Type
+	Linkage FuncLinkage
+}
+
+// VarSecinfo places a Var within a Datasec.
+type VarSecinfo struct {
+	Type   Type
+	Offset uint32
+	Size   uint32
+}
+
+// Datasec describes an ELF data section (e.g. .bss, .data, .rodata) and the
+// Vars placed within it.
+type Datasec struct {
+	base
+	Size uint32
+	Vars []VarSecinfo
+}
+
+// Float is a floating point type.
+type Float struct {
+	base
+	Size uint32
+}
+
+// DeclTag attaches a compiler annotation to a declaration.
+type DeclTag struct {
+	base
+	Target       Type
+	ComponentIdx int32
+}
+
+// TypeTag attaches a compiler annotation to a type.
+type TypeTag struct {
+	base
+	Target Type
+}
+
+func (k Kind) String() string {
+	switch k {
+	case KindUnknown:
+		return "Unknown"
+	case KindInt:
+		return "Int"
+	case KindPointer:
+		return "Pointer"
+	case KindArray:
+		return "Array"
+	case KindStruct:
+		return "Struct"
+	case KindUnion:
+		return "Union"
+	case KindEnum:
+		return "Enum"
+	case KindForward:
+		return "Forward"
+	case KindTypedef:
+		return "Typedef"
+	case KindVolatile:
+		return "Volatile"
+	case KindConst:
+		return "Const"
+	case KindRestrict:
+		return "Restrict"
+	case KindFunc:
+		return "Func"
+	case KindFuncProto:
+		return "FuncProto"
+	case KindVar:
+		return "Var"
+	case KindDatasec:
+		return "Datasec"
+	case KindFloat:
+		return "Float"
+	case KindDeclTag:
+		return "DeclTag"
+	case KindTypeTag:
+		return "TypeTag"
+	case KindEnum64:
+		return "Enum64"
+	default:
+		return fmt.Sprintf("Kind(%d)", uint8(k))
+	}
+}
@@ -0,0 +1,258 @@
+package libbpfgo
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// RuntimeLimitsInfo reports the CPU and memory limits the current process is
+// actually constrained to, as opposed to what the host machine has. In a
+// cgroup with a CPU quota (common under Kubernetes/systemd), sizing worker
+// pools off NumPossibleCPUs oversubscribes the container.
+type RuntimeLimitsInfo struct {
+	cpuQuota    float64 // fractional CPUs available to this cgroup, or -1 if unlimited
+	memoryLimit uint64  // bytes, or math.MaxUint64 if unlimited
+}
+
+// RuntimeLimits reads the calling process's own cgroup, resolved via
+// /proc/self/cgroup, to determine its effective CPU and memory limits. It
+// supports both cgroup v2 (cpu.max, memory.max) and cgroup v1
+// (cpu.cfs_quota_us/cpu.cfs_period_us, memory.limit_in_bytes).
+func RuntimeLimits() (*RuntimeLimitsInfo, error) {
+	selfPaths, err := readSelfCgroupPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	if isCgroupV2() {
+		return runtimeLimitsV2(selfPaths)
+	}
+	return runtimeLimitsV1(selfPaths)
+}
+
+// EffectiveCPUs returns the number of CPUs this process's cgroup quota
+// allows it to use concurrently, rounded up, and never more than
+// NumPossibleCPUs reports for the host. It is meant to size worker pools
+// that drain per-CPU maps or ring buffers without oversubscribing the
+// container.
+func (r *RuntimeLimitsInfo) EffectiveCPUs() int {
+	hostCPUs, err := NumPossibleCPUs()
+	if err != nil || hostCPUs <= 0 {
+		hostCPUs = runtime.NumCPU()
+	}
+
+	if r.cpuQuota < 0 {
+		return hostCPUs
+	}
+
+	cpus := int(math.Ceil(r.cpuQuota))
+	if cpus < 1 {
+		cpus = 1
+	}
+	if cpus > hostCPUs {
+		cpus = hostCPUs
+	}
+	return cpus
+}
+
+// MemoryLimit returns the cgroup's memory limit in bytes, or
+// math.MaxUint64 if the cgroup has no limit configured.
+func (r *RuntimeLimitsInfo) MemoryLimit() uint64 {
+	return r.memoryLimit
+}
+
+// SetGOMAXPROCSFromCgroup sets GOMAXPROCS to RuntimeLimits().EffectiveCPUs().
+// It is an opt-in for downstream consumers that want libbpfgo's worker pools
+// and the Go scheduler to agree on how many CPUs are actually available;
+// callers that already manage GOMAXPROCS themselves should not call this.
+func SetGOMAXPROCSFromCgroup() error {
+	limits, err := RuntimeLimits()
+	if err != nil {
+		return fmt.Errorf("failed to read cgroup limits: %w", err)
+	}
+	runtime.GOMAXPROCS(limits.EffectiveCPUs())
+	return nil
+}
+
+func isCgroupV2() bool {
+	_, err := os.Stat(cgroupRoot + "/cgroup.controllers")
+	return err == nil
+}
+
+// readSelfCgroupPaths parses /proc/self/cgroup, returning the calling
+// process's cgroup path for each controller it belongs to (v1), plus its
+// unified path under the key "" for cgroup v2.
+//
+// Each line has the form "<hierarchy-id>:<controller-list>:<path>"; v2's
+// single unified hierarchy is reported as "0::<path>".
+func readSelfCgroupPaths() (map[string]string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return nil, fmt.Errorf("reading /proc/self/cgroup: %w", err)
+	}
+	defer f.Close()
+
+	paths := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		hierarchyID, controllers, path := parts[0], parts[1], parts[2]
+
+		if hierarchyID == "0" && controllers == "" {
+			paths[""] = path
+			continue
+		}
+		for _, c := range strings.Split(controllers, ",") {
+			if c != "" {
+				paths[c] = path
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading /proc/self/cgroup: %w", err)
+	}
+	return paths, nil
+}
+
+func runtimeLimitsV2(selfPaths map[string]string) (*RuntimeLimitsInfo, error) {
+	base := filepath.Join(cgroupRoot, selfPaths[""])
+
+	quota, err := readCPUMax(filepath.Join(base, "cpu.max"))
+	if err != nil {
+		return nil, err
+	}
+
+	memLimit, err := readMemoryLimit(filepath.Join(base, "memory.max"), "max")
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuntimeLimitsInfo{cpuQuota: quota, memoryLimit: memLimit}, nil
+}
+
+func runtimeLimitsV1(selfPaths map[string]string) (*RuntimeLimitsInfo, error) {
+	cpuBase := filepath.Join(cgroupRoot, "cpu", selfPaths["cpu"])
+
+	quotaUs, err := readInt64File(filepath.Join(cpuBase, "cpu.cfs_quota_us"))
+	if err != nil {
+		return nil, err
+	}
+
+	quota := -1.0
+	if quotaUs > 0 {
+		periodUs, err := readInt64File(filepath.Join(cpuBase, "cpu.cfs_period_us"))
+		if err != nil {
+			return nil, err
+		}
+		if periodUs > 0 {
+			quota = float64(quotaUs) / float64(periodUs)
+		}
+	}
+
+	memBase := filepath.Join(cgroupRoot, "memory", selfPaths["memory"])
+	memLimit, err := readMemoryLimit(filepath.Join(memBase, "memory.limit_in_bytes"), "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuntimeLimitsInfo{cpuQuota: quota, memoryLimit: memLimit}, nil
+}
+
+// readCPUMax parses cgroup v2's "cpu.max", a single line of the form
+// "$MAX $PERIOD" (or "max $PERIOD" when unlimited), returning the fractional
+// number of CPUs it allows, or -1 if unlimited.
+func readCPUMax(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return -1, nil
+		}
+		return 0, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return -1, nil
+	}
+
+	fields := strings.Fields(scanner.Text())
+	if len(fields) != 2 || fields[0] == "max" {
+		return -1, nil
+	}
+
+	quota, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	period, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || period == 0 {
+		return 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return float64(quota) / float64(period), nil
+}
+
+func readMemoryLimit(path, unlimitedValue string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return math.MaxUint64, nil
+		}
+		return 0, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return math.MaxUint64, nil
+	}
+
+	value := strings.TrimSpace(scanner.Text())
+	if value == unlimitedValue {
+		return math.MaxUint64, nil
+	}
+
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	// cgroup v1 reports an effectively-unlimited sentinel rather than a
+	// dedicated "max" keyword.
+	const v1Unlimited = uint64(math.MaxInt64/4096) * 4096
+	if limit >= v1Unlimited {
+		return math.MaxUint64, nil
+	}
+
+	return limit, nil
+}
+
+func readInt64File(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return -1, nil
+		}
+		return 0, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return -1, nil
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(scanner.Text()), 10, 64)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// clangTriple maps a bpf2go target to the clang -target triple that produces
+// an object file of matching endianness.
+func (t target) clangTriple() string {
+	switch t {
+	case targetBPFEL:
+		return "bpfel"
+	case targetBPFEB:
+		return "bpfeb"
+	default:
+		panic("unknown target " + string(t))
+	}
+}
+
+// compileCC invokes clang to build srcPath into a .bpf.o for the given
+// target, so that callers can point bpf2go at C source instead of a
+// pre-built object when cross-compiling for both endiannesses.
+func compileCC(cc, srcPath string, t target, cflags []string) (string, error) {
+	outPath, err := os.CreateTemp("", "bpf2go-*.bpf.o")
+	if err != nil {
+		return "", fmt.Errorf("creating temp object file: %w", err)
+	}
+	outPath.Close()
+
+	args := append([]string{
+		"-target", t.clangTriple(),
+		"-c", srcPath,
+		"-o", outPath.Name(),
+	}, cflags...)
+
+	cmd := exec.Command(cc, args...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(outPath.Name())
+		return "", fmt.Errorf("running %s %v: %w", cc, args, err)
+	}
+
+	return outPath.Name(), nil
+}
@@ -0,0 +1,34 @@
+// Command bpf2go generates Go bindings from a compiled eBPF object file.
+//
+// It is meant to be invoked via `go generate` next to a `.bpf.o` produced by
+// clang, for example:
+//
+//	//go:generate go run github.com/khulnasoft-lab/libbpfgo/cmd/bpf2go -name Foo -target bpfel,bpfeb main.bpf.o
+//
+// For each requested target (bpfel and/or bpfeb) bpf2go writes a Go file
+// containing:
+//
+//   - an embedded byte slice of the object file,
+//   - a FooObjects struct with one *bpf.BPFMap/*bpf.BPFProg field per ELF
+//     section found in the object,
+//   - a LoadFooObjects constructor that loads the embedded bytes with
+//     bpf.NewModuleFromBufferArgs, calls BPFLoadObject, and populates the
+//     struct via GetMap/GetProgram.
+//
+// When the object carries a .BTF section, bpf2go also derives each map's key
+// and value type from it (mapKeyValueType in keyvalue.go): struct- and
+// union-typed keys/values become named Go struct declarations alongside
+// FooObjects, everything else maps to the matching builtin Go type, and a
+// per-CPU map type's value is instead reported as []byte, since its real
+// size depends on the possible-CPU count of the machine the generated code
+// eventually runs on, which may differ from the machine bpf2go ran on;
+// FooObjects gets a generated FieldValueSize() helper method for these maps
+// that computes the correct size at load time via bpf.CalcMapValueSize. A
+// map whose key/value BTF can't be resolved (notably the legacy
+// SEC("maps/name") declaration form) is simply left undocumented rather
+// than failing the generation.
+//
+// This removes the boilerplate every program under selftest/ repeats today
+// and lets consumers of this module ship a plain Go binary without also
+// shipping the .bpf.o file next to it.
+package main
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"debug/elf"
+	"fmt"
+	"runtime"
+)
+
+// nativeTarget maps the host architecture to the bpf2go target whose
+// byte order matches it, so `-target native` does the right thing when
+// cross-compilation isn't needed.
+func nativeTarget() target {
+	switch runtime.GOARCH {
+	case "s390x":
+		return targetBPFEB
+	default:
+		return targetBPFEL
+	}
+}
+
+// section describes a single map or program discovered in the object file.
+type section struct {
+	Name    string // map/program name, e.g. "events" or "kprobe/do_sys_open"
+	GoField string // exported struct field name derived from Name
+	IsMap   bool
+	// KeyType and ValueType are the Go types mapKeyValueType derived from
+	// the map's BTF, if any; empty when the map's key/value BTF couldn't
+	// be resolved (e.g. the legacy SEC("maps/name") declaration form,
+	// which carries no BTF key/value information of its own).
+	KeyType   string
+	ValueType string
+	// IsPerCPU reports whether the map is one of the BPF_MAP_TYPE_*PERCPU*
+	// types, whose value size depends on the number of possible CPUs on the
+	// machine the program ends up running on, not the machine bpf2go ran
+	// on. ValueType is "[]byte" for these; the real, correctly-sized value
+	// length is only knowable at load time, via the generated *ValueSize
+	// helper method.
+	IsPerCPU bool
+}
+
+const stSymTypeMask = 0xf
+const stSymTypeObject = 1 // STT_OBJECT
+
+// objectSections walks the ELF section headers of a compiled .bpf.o and
+// returns every section that corresponds to a BPF map or program, skipping
+// metadata sections such as .BTF, .BTF.ext, license and maps' BTF companions.
+//
+// Maps declared the legacy way (SEC("maps/name")) are reported one-to-one
+// with their section. Maps declared the modern, BTF-defined way all share a
+// single ".maps" ELF section containing one global variable per map, so
+// those are resolved via the symbol table instead: each STT_OBJECT symbol
+// defined in ".maps" becomes its own section entry, named after the
+// variable (which is also the map's name, per libbpf convention).
+func objectSections(path string) ([]section, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var sections []section
+	for _, sec := range f.Sections {
+		switch sec.Name {
+		case "", ".text", ".strtab", ".symtab", ".shstrtab", ".BTF", ".BTF.ext", "license", "version":
+			continue
+		}
+		if sec.Type != elf.SHT_PROGBITS || sec.Size == 0 {
+			continue
+		}
+
+		if sec.Name == ".maps" {
+			maps, err := btfMapSections(f, sec)
+			if err != nil {
+				return nil, fmt.Errorf("resolving BTF-defined maps in %s: %w", path, err)
+			}
+			sections = append(sections, maps...)
+			continue
+		}
+
+		isLegacyMap := hasPrefix(sec.Name, "maps/")
+
+		// Anything else has to be an actual SEC("prog_name") program to
+		// belong here. Data sections compilers emit into essentially every
+		// .bpf.o (.rodata, .rodata.str1.1, .data, ...) are SHT_PROGBITS too,
+		// but aren't executable code, so they'd otherwise be swept in here
+		// and generate a GetProgram call that fails at runtime.
+		if !isLegacyMap && sec.Flags&elf.SHF_EXECINSTR == 0 {
+			continue
+		}
+
+		sections = append(sections, section{
+			Name:    sec.Name,
+			GoField: exportedIdentifier(sec.Name),
+			IsMap:   isLegacyMap,
+		})
+	}
+
+	return sections, nil
+}
+
+// btfMapSections returns one section per BTF-defined map (global variable)
+// placed in the ELF ".maps" section, named after the variable rather than
+// the section itself.
+func btfMapSections(f *elf.File, mapsSec *elf.Section) ([]section, error) {
+	mapsIndex, err := sectionIndex(f, mapsSec)
+	if err != nil {
+		return nil, err
+	}
+
+	syms, err := f.Symbols()
+	if err != nil {
+		return nil, fmt.Errorf("reading symbol table: %w", err)
+	}
+
+	var sections []section
+	for _, sym := range syms {
+		if sym.Section != mapsIndex || sym.Name == "" {
+			continue
+		}
+		if sym.Info&stSymTypeMask != stSymTypeObject {
+			continue
+		}
+		sections = append(sections, section{
+			Name:    sym.Name,
+			GoField: exportedIdentifier(sym.Name),
+			IsMap:   true,
+		})
+	}
+
+	return sections, nil
+}
+
+func sectionIndex(f *elf.File, sec *elf.Section) (elf.SectionIndex, error) {
+	for i, s := range f.Sections {
+		if s == sec {
+			return elf.SectionIndex(i), nil
+		}
+	}
+	return 0, fmt.Errorf("section %s not found in its own file", sec.Name)
+}
+
+func hasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestHasPrefix(t *testing.T) {
+	cases := []struct {
+		s, prefix string
+		want      bool
+	}{
+		{"maps/events", "maps/", true},
+		{"maps", "maps/", false},
+		{"", "maps/", false},
+		{"events", "", true},
+	}
+	for _, c := range cases {
+		if got := hasPrefix(c.s, c.prefix); got != c.want {
+			t.Errorf("hasPrefix(%q, %q) = %v, want %v", c.s, c.prefix, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/khulnasoft-lab/libbpfgo/btf"
+)
+
+// exportedIdentifier turns an ELF/BTF name such as "maps/events" or
+// "kprobe/do_sys_open" into a Go-exported identifier, e.g. "Events" or
+// "KprobeDoSysOpen".
+func exportedIdentifier(name string) string {
+	name = strings.TrimPrefix(name, "maps/")
+
+	var b strings.Builder
+	upperNext := true
+	for _, r := range name {
+		switch {
+		case r == '/' || r == '_' || r == '.' || r == '-':
+			upperNext = true
+		case upperNext:
+			b.WriteRune(toUpper(r))
+			upperNext = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func toUpper(r rune) rune {
+	if r >= 'a' && r <= 'z' {
+		return r - ('a' - 'A')
+	}
+	return r
+}
+
+type genData struct {
+	Package     string
+	Name        string
+	Target      target
+	GoArch      string
+	ObjectVar   string
+	ObjectFile  string
+	Maps        []section
+	Progs       []section
+	TypeDecls   []string
+	NeedsUnsafe bool
+}
+
+var objectsTemplate = template.Must(template.New("objects").Parse(`// Code generated by bpf2go; DO NOT EDIT.
+
+//go:build {{.GoArch}}
+
+package {{.Package}}
+
+import (
+	_ "embed"
+	"fmt"
+{{- if .NeedsUnsafe}}
+	"unsafe"
+{{- end}}
+
+	bpf "github.com/khulnasoft-lab/libbpfgo"
+)
+
+//go:embed {{.ObjectFile}}
+var {{.ObjectVar}} []byte
+
+{{range .TypeDecls}}
+{{.}}
+{{end}}
+// {{.Name}}Objects holds the maps and programs contained in the compiled
+// BPF object, keyed by their ELF section name.
+type {{.Name}}Objects struct {
+{{- range .Maps}}
+	// {{.GoField}} is backed by map "{{.Name}}". Keys are {{if .KeyType}}{{.KeyType}}{{else}}unresolved (no BTF key/value info){{end}}{{if .ValueType}}, values are {{.ValueType}}{{end}}.
+	{{.GoField}} *bpf.BPFMap
+{{- end}}
+{{- range .Progs}}
+	{{.GoField}} *bpf.BPFProg
+{{- end}}
+	module *bpf.Module
+}
+
+// Close releases the underlying BPF module and every map/program it owns.
+func (o *{{.Name}}Objects) Close() error {
+	o.module.Close()
+	return nil
+}
+
+// Load{{.Name}}Objects loads the embedded {{.Name}} BPF object and resolves
+// its maps and programs into obj. The caller must call obj.Close() once done.
+func Load{{.Name}}Objects(obj *{{.Name}}Objects, opts *bpf.NewModuleArgs) error {
+	args := bpf.NewModuleArgs{}
+	if opts != nil {
+		args = *opts
+	}
+	args.BPFObjBuff = {{.ObjectVar}}
+
+	module, err := bpf.NewModuleFromBufferArgs(args)
+	if err != nil {
+		return fmt.Errorf("loading {{.Name}} object: %w", err)
+	}
+
+	if err := module.BPFLoadObject(); err != nil {
+		module.Close()
+		return fmt.Errorf("loading {{.Name}} object: %w", err)
+	}
+
+{{range .Maps}}
+	obj.{{.GoField}}, err = module.GetMap("{{.Name}}")
+	if err != nil {
+		module.Close()
+		return fmt.Errorf("resolving map {{.Name}}: %w", err)
+	}
+{{end}}
+{{range .Progs}}
+	obj.{{.GoField}}, err = module.GetProgram("{{.Name}}")
+	if err != nil {
+		module.Close()
+		return fmt.Errorf("resolving program {{.Name}}: %w", err)
+	}
+{{end}}
+	obj.module = module
+	return nil
+}
+{{range .Maps}}
+{{- if .IsPerCPU}}
+// {{.GoField}}ValueSize returns the size, in bytes, of one {{.GoField}} value
+// as read back by bpf.BPFMap.LookupBatchPerCPU et al: one copy of the map's
+// declared value per possible CPU on this machine, rounded up to 8 bytes per
+// copy. This is computed at load time rather than generation time, since the
+// CPU count of the machine bpf2go ran on may differ from the machine this
+// program eventually runs on.
+func (o *{{$.Name}}Objects) {{.GoField}}ValueSize() (int, error) {
+	info, err := bpf.GetMapInfoByFD(o.{{.GoField}}.FD())
+	if err != nil {
+		return 0, fmt.Errorf("getting info for map {{.Name}}: %w", err)
+	}
+	return bpf.CalcMapValueSize(int(info.ValueSize), info.Type)
+}
+{{- end}}
+{{- end}}
+`))
+
+// generate writes one Go file per requested target into cfg.outDir. If
+// cfg.objPath is C source rather than a pre-built object, it is compiled once
+// per target with clang so that a single invocation can produce both the
+// bpfel and bpfeb variants.
+func generate(cfg config) error {
+	pkgName := cfg.pkgName
+	if pkgName == "" {
+		pkgName = strings.ToLower(cfg.name)
+	}
+
+	fromSource := strings.HasSuffix(cfg.objPath, ".c")
+
+	for _, t := range cfg.targets {
+		objPath := cfg.objPath
+		if fromSource {
+			cc := cfg.ccTarget
+			if cc == "" {
+				cc = "clang"
+			}
+
+			compiled, err := compileCC(cc, cfg.objPath, t, nil)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(compiled)
+			objPath = compiled
+		}
+
+		objBytes, err := os.ReadFile(objPath)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", objPath, err)
+		}
+
+		sections, err := objectSections(objPath)
+		if err != nil {
+			return err
+		}
+
+		var maps, progs []section
+		for _, s := range sections {
+			if s.IsMap {
+				maps = append(maps, s)
+			} else {
+				progs = append(progs, s)
+			}
+		}
+
+		typeDecls, err := resolveMapTypes(objPath, maps)
+		if err != nil {
+			return err
+		}
+
+		if err := generateTarget(cfg, t, pkgName, maps, progs, typeDecls, objBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveMapTypes fills in each map section's KeyType/ValueType from the
+// object's BTF, in place, and returns the Go struct declarations those types
+// reference. A map whose BTF key/value can't be resolved (most commonly the
+// legacy SEC("maps/name") declaration form, which has no BTF var of its own
+// to look up) is left with empty KeyType/ValueType rather than failing the
+// whole run.
+func resolveMapTypes(objPath string, maps []section) ([]string, error) {
+	spec, err := loadObjectBTF(objPath)
+	if err != nil || spec == nil {
+		return nil, err
+	}
+
+	tg := newTypeGen()
+	for i := range maps {
+		keyType, valueType, isPerCPU, err := mapKeyValueType(maps[i], spec, tg)
+		if err != nil {
+			continue
+		}
+		maps[i].KeyType = keyType
+		maps[i].ValueType = valueType
+		maps[i].IsPerCPU = isPerCPU
+	}
+
+	return tg.decls, nil
+}
+
+// loadObjectBTF parses the .BTF ELF section of the object at path, if any.
+// Objects compiled without -g (no debug info) have no .BTF section; that's
+// not an error, it just means map key/value types can't be derived.
+func loadObjectBTF(path string) (*btf.Spec, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	sec := f.Section(".BTF")
+	if sec == nil {
+		return nil, nil
+	}
+
+	r := sec.Open()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading .BTF section of %s: %w", path, err)
+	}
+
+	spec, err := btf.LoadSpecFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing .BTF section of %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+func generateTarget(cfg config, t target, pkgName string, maps, progs []section, typeDecls []string, objBytes []byte) error {
+	objectVar := fmt.Sprintf("_%sBytes", strings.ToLower(cfg.name))
+	objectFile := fmt.Sprintf("%s_%s.o", strings.ToLower(cfg.name), t)
+
+	data := genData{
+		Package:     pkgName,
+		Name:        cfg.name,
+		Target:      t,
+		GoArch:      t.goArch(),
+		ObjectVar:   objectVar,
+		ObjectFile:  objectFile,
+		Maps:        maps,
+		Progs:       progs,
+		TypeDecls:   typeDecls,
+		NeedsUnsafe: strings.Contains(strings.Join(typeDecls, "\n"), "unsafe.Pointer"),
+	}
+
+	var buf bytes.Buffer
+	if err := objectsTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("rendering template: %w", err)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated source: %w", err)
+	}
+
+	outPath := filepath.Join(cfg.outDir, fmt.Sprintf("%s_%s.go", strings.ToLower(cfg.name), t))
+	if err := os.WriteFile(outPath, formatted, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", outPath, err)
+	}
+
+	objOutPath := filepath.Join(cfg.outDir, fmt.Sprintf("%s_%s.o", strings.ToLower(cfg.name), t))
+	return os.WriteFile(objOutPath, objBytes, 0o644)
+}
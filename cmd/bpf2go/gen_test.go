@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestExportedIdentifier(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"events", "Events"},
+		{"maps/events", "Events"},
+		{"kprobe/do_sys_open", "KprobeDoSysOpen"},
+		{"my-prog.name_here", "MyProgNameHere"},
+	}
+	for _, c := range cases {
+		if got := exportedIdentifier(c.in); got != c.want {
+			t.Errorf("exportedIdentifier(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
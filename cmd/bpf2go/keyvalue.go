@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/khulnasoft-lab/libbpfgo/btf"
+)
+
+// Mirrors the BPF_MAP_TYPE_* enum in the UAPI header (and MapType in the
+// root package); only the per-CPU variants matter here, since they're the
+// only ones whose Go value type differs from the map's declared BTF value
+// type.
+const (
+	bpfMapTypePerCPUHash          = 5
+	bpfMapTypePerCPUArray         = 6
+	bpfMapTypeLRUPerCPUHash       = 10
+	bpfMapTypePerCPUCgroupStorage = 21
+)
+
+func isPerCPUMapType(n uint32) bool {
+	switch n {
+	case bpfMapTypePerCPUHash, bpfMapTypePerCPUArray, bpfMapTypeLRUPerCPUHash, bpfMapTypePerCPUCgroupStorage:
+		return true
+	default:
+		return false
+	}
+}
+
+// typeGen accumulates the named Go struct types a set of mapKeyValueType
+// calls generate, keyed by BTF type id so the same BTF struct referenced by
+// more than one map only produces one Go type declaration.
+type typeGen struct {
+	decls   []string
+	named   map[btf.TypeID]string
+	anonSeq int
+}
+
+func newTypeGen() *typeGen {
+	return &typeGen{named: make(map[btf.TypeID]string)}
+}
+
+// mapKeyValueType derives the Go type for a BTF-defined map's key and value
+// member, matching the anonymous struct layout libbpf's __uint/__type macros
+// produce:
+//
+//	struct {
+//		__uint(type, BPF_MAP_TYPE_HASH);
+//		__uint(max_entries, 1024);
+//		__type(key, int);
+//		__type(value, struct event);
+//	} my_map SEC(".maps");
+//
+// Struct- and union-typed keys/values are emitted as named Go struct
+// declarations via tg; everything else resolves to a builtin Go type. A
+// per-CPU map type's value is reported as "[]byte" with isPerCPU set,
+// rather than a fixed-size array: its real size depends on the number of
+// possible CPUs on the machine the generated code eventually runs on, which
+// isn't known until then, so it can't be baked in at generation time (the
+// generated *ValueSize helper method computes it at load time instead, via
+// bpf.CalcMapValueSize).
+func mapKeyValueType(sec section, spec *btf.Spec, tg *typeGen) (keyType, valueType string, isPerCPU bool, err error) {
+	def, err := mapBTFDef(spec, sec.Name)
+	if err != nil {
+		return "", "", false, fmt.Errorf("resolving BTF definition of map %s: %w", sec.Name, err)
+	}
+
+	keyMember, ok := memberByName(def, "key")
+	if !ok {
+		return "", "", false, fmt.Errorf("map %s has no BTF \"key\" member", sec.Name)
+	}
+	keyElem, ok := pointerTarget(keyMember.Type)
+	if !ok {
+		return "", "", false, fmt.Errorf("map %s's BTF \"key\" member is not a pointer", sec.Name)
+	}
+	keyType, err = tg.goType(keyElem)
+	if err != nil {
+		return "", "", false, fmt.Errorf("map %s key: %w", sec.Name, err)
+	}
+
+	valueMember, ok := memberByName(def, "value")
+	if !ok {
+		return "", "", false, fmt.Errorf("map %s has no BTF \"value\" member", sec.Name)
+	}
+	valueElem, ok := pointerTarget(valueMember.Type)
+	if !ok {
+		return "", "", false, fmt.Errorf("map %s's BTF \"value\" member is not a pointer", sec.Name)
+	}
+
+	if mapType, ok := memberMapType(def); ok && isPerCPUMapType(mapType) {
+		if _, err := btfSizeof(valueElem); err != nil {
+			return "", "", false, fmt.Errorf("map %s value: %w", sec.Name, err)
+		}
+		return keyType, "[]byte", true, nil
+	}
+
+	valueType, err = tg.goType(valueElem)
+	if err != nil {
+		return "", "", false, fmt.Errorf("map %s value: %w", sec.Name, err)
+	}
+	return keyType, valueType, false, nil
+}
+
+// mapBTFDef returns the anonymous struct BTF describes for the map variable
+// named name (the variable libbpf's __uint/__type macros build up).
+func mapBTFDef(spec *btf.Spec, name string) (*btf.Struct, error) {
+	t, err := spec.TypeByName(name)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := t.(*btf.Var)
+	if !ok {
+		return nil, fmt.Errorf("BTF type is a %T, not a Var", t)
+	}
+	s, ok := v.Type.(*btf.Struct)
+	if !ok {
+		return nil, fmt.Errorf("BTF type of %s is a %T, not a Struct", name, v.Type)
+	}
+	return s, nil
+}
+
+func memberByName(s *btf.Struct, name string) (btf.Member, bool) {
+	for _, m := range s.Members {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return btf.Member{}, false
+}
+
+// memberMapType reads the map's BPF_MAP_TYPE_* value back out of its
+// __uint(type, ...) member, which libbpf encodes as a pointer to an array
+// whose length is the value itself.
+func memberMapType(def *btf.Struct) (uint32, bool) {
+	m, ok := memberByName(def, "type")
+	if !ok {
+		return 0, false
+	}
+	ptr, ok := m.Type.(*btf.Pointer)
+	if !ok {
+		return 0, false
+	}
+	arr, ok := ptr.Target.(*btf.Array)
+	if !ok {
+		return 0, false
+	}
+	return arr.Nelems, true
+}
+
+func pointerTarget(t btf.Type) (btf.Type, bool) {
+	ptr, ok := t.(*btf.Pointer)
+	if !ok {
+		return nil, false
+	}
+	return ptr.Target, true
+}
+
+// goType resolves a BTF type to a Go type name, registering a named struct
+// declaration in tg.decls the first time a given Struct/Union id is seen.
+func (tg *typeGen) goType(t btf.Type) (string, error) {
+	switch v := t.(type) {
+	case *btf.Int:
+		return intGoType(v), nil
+	case *btf.Float:
+		if v.Size == 4 {
+			return "float32", nil
+		}
+		return "float64", nil
+	case *btf.Enum:
+		if v.Size == 8 {
+			return "int64", nil
+		}
+		return "int32", nil
+	case *btf.Typedef:
+		return tg.goType(v.Target)
+	case *btf.Volatile:
+		return tg.goType(v.Target)
+	case *btf.Const:
+		return tg.goType(v.Target)
+	case *btf.Restrict:
+		return tg.goType(v.Target)
+	case *btf.Pointer:
+		return "unsafe.Pointer", nil
+	case *btf.Array:
+		elem, err := tg.goType(v.Element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[%d]%s", v.Nelems, elem), nil
+	case *btf.Struct:
+		return tg.structType(v.TypeName(), v.Members)
+	case *btf.Union:
+		return tg.structType(v.TypeName(), v.Members)
+	default:
+		return "", fmt.Errorf("BTF kind %T has no Go equivalent", t)
+	}
+}
+
+func (tg *typeGen) structType(name string, members []btf.Member) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "struct {\n")
+	for _, m := range members {
+		fieldType, err := tg.goType(m.Type)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", m.Name, err)
+		}
+		fmt.Fprintf(&b, "\t%s %s\n", exportedIdentifier(m.Name), fieldType)
+	}
+	b.WriteString("}")
+
+	goName := name
+	if goName == "" {
+		tg.anonSeq++
+		goName = fmt.Sprintf("AnonType%d", tg.anonSeq)
+	} else {
+		goName = exportedIdentifier(goName)
+	}
+	for _, have := range tg.decls {
+		if strings.HasPrefix(have, "type "+goName+" ") {
+			return goName, nil
+		}
+	}
+	tg.decls = append(tg.decls, fmt.Sprintf("type %s %s", goName, b.String()))
+	return goName, nil
+}
+
+func intGoType(i *btf.Int) string {
+	if i.Encoding == btf.IntBool {
+		return "bool"
+	}
+	signed := i.Encoding == btf.IntSigned
+	switch {
+	case i.Size <= 1:
+		if signed {
+			return "int8"
+		}
+		return "uint8"
+	case i.Size <= 2:
+		if signed {
+			return "int16"
+		}
+		return "uint16"
+	case i.Size <= 4:
+		if signed {
+			return "int32"
+		}
+		return "uint32"
+	default:
+		if signed {
+			return "int64"
+		}
+		return "uint64"
+	}
+}
+
+// btfSizeof returns the in-memory size BTF reports for t, so per-CPU value
+// types can be rounded and multiplied without a second, Go-side definition
+// of every BTF kind's size rules.
+func btfSizeof(t btf.Type) (int, error) {
+	switch v := t.(type) {
+	case *btf.Int:
+		return int(v.Size), nil
+	case *btf.Float:
+		return int(v.Size), nil
+	case *btf.Enum:
+		return int(v.Size), nil
+	case *btf.Struct:
+		return int(v.Size), nil
+	case *btf.Union:
+		return int(v.Size), nil
+	case *btf.Pointer:
+		return 8, nil
+	case *btf.Array:
+		elemSize, err := btfSizeof(v.Element)
+		if err != nil {
+			return 0, err
+		}
+		return elemSize * int(v.Nelems), nil
+	case *btf.Typedef:
+		return btfSizeof(v.Target)
+	case *btf.Volatile:
+		return btfSizeof(v.Target)
+	case *btf.Const:
+		return btfSizeof(v.Target)
+	case *btf.Restrict:
+		return btfSizeof(v.Target)
+	default:
+		return 0, fmt.Errorf("BTF kind %T has no known size", t)
+	}
+}
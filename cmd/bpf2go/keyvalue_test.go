@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/khulnasoft-lab/libbpfgo/btf"
+)
+
+func TestIsPerCPUMapType(t *testing.T) {
+	perCPU := []uint32{bpfMapTypePerCPUHash, bpfMapTypePerCPUArray, bpfMapTypeLRUPerCPUHash, bpfMapTypePerCPUCgroupStorage}
+	for _, n := range perCPU {
+		if !isPerCPUMapType(n) {
+			t.Errorf("isPerCPUMapType(%d) = false, want true", n)
+		}
+	}
+	if isPerCPUMapType(1) { // BPF_MAP_TYPE_HASH
+		t.Error("isPerCPUMapType(1) = true, want false")
+	}
+}
+
+func TestIntGoType(t *testing.T) {
+	cases := []struct {
+		size     uint32
+		encoding btf.IntEncoding
+		want     string
+	}{
+		{size: 1, encoding: btf.IntBool, want: "bool"},
+		{size: 1, encoding: btf.IntPlain, want: "uint8"},
+		{size: 1, encoding: btf.IntSigned, want: "int8"},
+		{size: 2, encoding: btf.IntSigned, want: "int16"},
+		{size: 4, encoding: btf.IntPlain, want: "uint32"},
+		{size: 8, encoding: btf.IntSigned, want: "int64"},
+	}
+	for _, c := range cases {
+		i := &btf.Int{Size: c.size, Encoding: c.encoding}
+		if got := intGoType(i); got != c.want {
+			t.Errorf("intGoType(size=%d, encoding=%d) = %q, want %q", c.size, c.encoding, got, c.want)
+		}
+	}
+}
+
+func TestBtfSizeof(t *testing.T) {
+	u32 := &btf.Int{Size: 4}
+	arr := &btf.Array{Element: u32, Nelems: 10}
+
+	got, err := btfSizeof(arr)
+	if err != nil {
+		t.Fatalf("btfSizeof: %v", err)
+	}
+	if got != 40 {
+		t.Errorf("btfSizeof(array of 10 u32) = %d, want 40", got)
+	}
+}
+
+func TestMemberMapType(t *testing.T) {
+	typeValue := &btf.Array{Nelems: uint32(bpfMapTypeLRUPerCPUHash)}
+	def := &btf.Struct{
+		Members: []btf.Member{
+			{Name: "type", Type: &btf.Pointer{Target: typeValue}},
+		},
+	}
+
+	got, ok := memberMapType(def)
+	if !ok {
+		t.Fatal("memberMapType: not found")
+	}
+	if got != bpfMapTypeLRUPerCPUHash {
+		t.Errorf("memberMapType = %d, want %d", got, bpfMapTypeLRUPerCPUHash)
+	}
+}
+
+func TestMemberMapTypeMissing(t *testing.T) {
+	def := &btf.Struct{}
+	if _, ok := memberMapType(def); ok {
+		t.Error("memberMapType on a struct with no \"type\" member should report not-found")
+	}
+}
+
+func TestGoTypeAnonStructGetsUniqueNames(t *testing.T) {
+	tg := newTypeGen()
+	u32 := &btf.Int{Size: 4}
+	anon := &btf.Struct{Members: []btf.Member{{Name: "x", Type: u32}}}
+
+	first, err := tg.goType(anon)
+	if err != nil {
+		t.Fatalf("goType: %v", err)
+	}
+	second, err := tg.goType(&btf.Struct{Members: []btf.Member{{Name: "y", Type: u32}}})
+	if err != nil {
+		t.Fatalf("goType: %v", err)
+	}
+
+	if first == second {
+		t.Errorf("two distinct anonymous structs both got Go type %q", first)
+	}
+	if len(tg.decls) != 2 {
+		t.Errorf("got %d decls, want 2", len(tg.decls))
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// target is a single endianness variant bpf2go generates bindings for.
+type target string
+
+const (
+	targetBPFEL target = "bpfel"
+	targetBPFEB target = "bpfeb"
+)
+
+func (t target) goArch() string {
+	switch t {
+	case targetBPFEL:
+		return "amd64"
+	case targetBPFEB:
+		return "s390x"
+	default:
+		panic("unknown target " + string(t))
+	}
+}
+
+type config struct {
+	name     string // Go identifier prefix, e.g. "Foo" -> FooObjects
+	outDir   string
+	pkgName  string
+	objPath  string
+	targets  []target
+	ccTarget string // compiler to invoke when objPath is C source, e.g. "clang"
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "bpf2go:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("bpf2go", flag.ContinueOnError)
+
+	name := fs.String("name", "", "Go identifier prefix for generated types, e.g. Foo")
+	outDir := fs.String("output-dir", ".", "directory to write generated files to")
+	pkgName := fs.String("package", "", "package name for generated files (defaults to the directory's package)")
+	targetsFlag := fs.String("target", "bpfel,bpfeb", "comma-separated list of target endiannesses: bpfel, bpfeb, or native")
+	cc := fs.String("cc", "clang", "compiler to invoke when the input is C source rather than a pre-built .bpf.o")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one .bpf.o argument, got %d", fs.NArg())
+	}
+
+	targets, err := parseTargets(*targetsFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg := config{
+		name:     *name,
+		outDir:   *outDir,
+		pkgName:  *pkgName,
+		objPath:  fs.Arg(0),
+		targets:  targets,
+		ccTarget: *cc,
+	}
+
+	return generate(cfg)
+}
+
+func parseTargets(s string) ([]target, error) {
+	var targets []target
+	for _, part := range strings.Split(s, ",") {
+		switch target(strings.TrimSpace(part)) {
+		case targetBPFEL:
+			targets = append(targets, targetBPFEL)
+		case targetBPFEB:
+			targets = append(targets, targetBPFEB)
+		case "native":
+			targets = append(targets, nativeTarget())
+		default:
+			return nil, fmt.Errorf("unknown target %q, want bpfel, bpfeb or native", part)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets requested")
+	}
+	return targets, nil
+}
@@ -0,0 +1,76 @@
+package libbpfgo
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// Error wraps a failed bpf(2) syscall with the operation that triggered it
+// and, when available, the verifier log produced by a failing BPF_PROG_LOAD.
+// It satisfies Unwrap() []error so that both errors.Is(err, unix.EPERM) and
+// errors.Is(err, bpf.ErrKeyNotExist)-style semantic checks work against the
+// same error value.
+type Error struct {
+	// Op names the libbpfgo call that failed, e.g. "GetMapInfoByFD".
+	Op string
+	// Errno is the raw errno the kernel returned.
+	Errno syscall.Errno
+	// Log is the verifier log, populated only when Op is a BPF_PROG_LOAD failure.
+	Log string
+
+	// sentinel, when non-nil, is a semantic classification of Errno that
+	// callers can match with errors.Is instead of comparing Errno directly.
+	sentinel error
+}
+
+func (e *Error) Error() string {
+	if e.Log != "" {
+		return fmt.Sprintf("%s: %s\nverifier log:\n%s", e.Op, e.Errno, e.Log)
+	}
+	return fmt.Sprintf("%s: %s", e.Op, e.Errno)
+}
+
+// Unwrap lets errors.Is/errors.As match both the underlying syscall.Errno and
+// any semantic sentinel (ErrKeyNotExist, ErrNotSupported, ...) this error
+// was classified as.
+func (e *Error) Unwrap() []error {
+	if e.sentinel != nil {
+		return []error{e.Errno, e.sentinel}
+	}
+	return []error{e.Errno}
+}
+
+// Sentinel semantic conditions, matched with errors.Is instead of raw errnos.
+var (
+	ErrNotSupported = errors.New("bpf: operation not supported by this kernel")
+	ErrKeyNotExist  = errors.New("bpf: key does not exist")
+	ErrKeyExist     = errors.New("bpf: key already exists")
+)
+
+// newError builds an *Error for a failed bpf(2) command, classifying errno
+// into one of the sentinel errors above when applicable.
+func newError(op string, errno syscall.Errno) *Error {
+	return &Error{Op: op, Errno: errno, sentinel: classifyErrno(errno)}
+}
+
+// newLoadError is newError for a failing BPF_PROG_LOAD, additionally
+// carrying the verifier log.
+func newLoadError(op string, errno syscall.Errno, log string) *Error {
+	err := newError(op, errno)
+	err.Log = log
+	return err
+}
+
+func classifyErrno(errno syscall.Errno) error {
+	switch errno {
+	case syscall.ENOENT:
+		return ErrKeyNotExist
+	case syscall.EEXIST:
+		return ErrKeyExist
+	case syscall.EOPNOTSUPP, syscall.ENOTSUP:
+		return ErrNotSupported
+	default:
+		return nil
+	}
+}
@@ -81,7 +81,7 @@ func SetStrictMode(mode LibbpfStrictMode) {
 func BPFProgramTypeIsSupported(progType BPFProgType) (bool, error) {
 	supportedC := C.libbpf_probe_bpf_prog_type(C.enum_bpf_prog_type(int(progType)), nil)
 	if supportedC < 1 {
-		return false, syscall.Errno(-supportedC)
+		return false, newError("BPFProgramTypeIsSupported", syscall.Errno(-supportedC))
 	}
 
 	return supportedC == 1, nil
@@ -94,7 +94,7 @@ func BPFProgramTypeIsSupported(progType BPFProgType) (bool, error) {
 func BPFMapTypeIsSupported(mapType MapType) (bool, error) {
 	supportedC := C.libbpf_probe_bpf_map_type(C.enum_bpf_map_type(int(mapType)), nil)
 	if supportedC < 1 {
-		return false, syscall.Errno(-supportedC)
+		return false, newError("BPFMapTypeIsSupported", syscall.Errno(-supportedC))
 	}
 
 	return supportedC == 1, nil
@@ -104,12 +104,14 @@ func BPFHelperIsSupported(progType BPFProgType, funcId string) (bool, error) {
 	retC := C.libbpf_probe_bpf_helper(C.enum_bpf_prog_type(int(progType)), nil)
 
 	if retC < 0 {
-		return false, fmt.Errorf("operation failed for function `%s` with program type `%s`: %w", funcId, progType, syscall.Errno(-retC))
+		return false, fmt.Errorf("operation failed for function `%s` with program type `%s`: %w",
+			funcId, progType, newError("BPFHelperIsSupported", syscall.Errno(-retC)))
 	}
 
 	// Handle unexpected errno values returned by libbpf.
 	if errno := syscall.Errno(-retC); errno != 0 {
-		return false, fmt.Errorf("unexpected errno for function `%s` with program type `%s`: %v", funcId, progType, errno)
+		return false, fmt.Errorf("unexpected errno for function `%s` with program type `%s`: %w",
+			funcId, progType, newError("BPFHelperIsSupported", errno))
 	}
 
 	// If retC == 1 and running with capabilities, the helper is supported.
@@ -123,7 +125,7 @@ func BPFHelperIsSupported(progType BPFProgType, funcId string) (bool, error) {
 func NumPossibleCPUs() (int, error) {
 	nCPUsC := C.libbpf_num_possible_cpus()
 	if nCPUsC < 0 {
-		return 0, fmt.Errorf("failed to retrieve the number of CPUs: %w", syscall.Errno(-nCPUsC))
+		return 0, newError("NumPossibleCPUs", syscall.Errno(-nCPUsC))
 	}
 
 	return int(nCPUsC), nil
@@ -0,0 +1,21 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"syscall"
+)
+
+// GetLinkFDByID returns a file descriptor for the link identified by id,
+// obtained via the BPF_LINK_GET_FD_BY_ID bpf(2) command.
+func GetLinkFDByID(id uint32) (int, error) {
+	fdC := C.bpf_link_get_fd_by_id(C.uint(id))
+	if fdC < 0 {
+		return -1, newError("GetLinkFDByID", syscall.Errno(-fdC))
+	}
+	return int(fdC), nil
+}
@@ -0,0 +1,107 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// PerCPUValues holds one value per CPU for a single key, as returned by
+// LookupBatchPerCPU.
+type PerCPUValues [][]byte
+
+// LookupBatchPerCPU looks up every key in keys against a per-CPU map type
+// (MapTypePerCPUHash, MapTypePerCPUArray, ...), fanning the work out across
+// a worker pool sized to min(NumPossibleCPUs, RuntimeLimits().EffectiveCPUs())
+// rather than one goroutine per host CPU, so aggregating a per-CPU map from
+// inside a CPU-quota-limited cgroup doesn't oversubscribe the container.
+func (m *BPFMap) LookupBatchPerCPU(keys [][]byte) ([]PerCPUValues, error) {
+	limits, err := RuntimeLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	workers := limits.EffectiveCPUs()
+	if hostCPUs, err := NumPossibleCPUs(); err == nil && hostCPUs > 0 && hostCPUs < workers {
+		workers = hostCPUs
+	}
+	if workers > len(keys) {
+		workers = len(keys)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]PerCPUValues, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				values, err := m.lookupPerCPU(keys[i])
+				results[i] = values
+				errs[i] = err
+			}
+		}()
+	}
+
+	for i := range keys {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// lookupPerCPU issues a single BPF_MAP_LOOKUP_ELEM for key. For a per-CPU map
+// type the kernel fills in one value per possible CPU in that single
+// syscall, packed into a buffer of roundUp(ValueSize, 8) * NumPossibleCPUs
+// bytes; there is no need, and no kernel API, for a separate lookup per CPU.
+func (m *BPFMap) lookupPerCPU(key []byte) (PerCPUValues, error) {
+	info, err := GetMapInfoByFD(m.FD())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get info for map %s: %w", m.Name(), err)
+	}
+
+	numCPU, err := NumPossibleCPUs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get number of possible CPUs: %w", err)
+	}
+
+	bufSize, err := CalcMapValueSize(int(info.ValueSize), info.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to size per-CPU buffer for map %s: %w", m.Name(), err)
+	}
+	buf := make([]byte, bufSize)
+
+	retC := C.bpf_map_lookup_elem(C.int(m.FD()), unsafe.Pointer(&key[0]), unsafe.Pointer(&buf[0]))
+	if retC < 0 {
+		return nil, newError(fmt.Sprintf("BPFMap.LookupBatchPerCPU(%s)", m.Name()), syscall.Errno(-retC))
+	}
+
+	elemSize := bufSize / numCPU
+	values := make(PerCPUValues, numCPU)
+	for i := 0; i < numCPU; i++ {
+		values[i] = buf[i*elemSize : i*elemSize+int(info.ValueSize)]
+	}
+	return values, nil
+}
@@ -9,6 +9,7 @@ import "C"
 import (
 	"fmt"
 	"syscall"
+	"unsafe"
 )
 
 type MapType uint32
@@ -102,7 +103,7 @@ func (t MapType) Name() string {
 type MapFlag uint32
 
 const (
-	MapFlagUpdateAny     MapFlag = iota
+	MapFlagUpdateAny MapFlag = iota
 	MapFlagUpdateNoExist
 	MapFlagUpdateExist
 	MapFlagFLock
@@ -126,22 +127,48 @@ type BPFMapInfo struct {
 	MapExtra              uint64
 }
 
+// GetMapFDByID returns a file descriptor for the map identified by id, obtained
+// via the BPF_MAP_GET_FD_BY_ID bpf(2) command.
 func GetMapFDByID(id uint32) (int, error) {
-	fd, err := syscall.Open(fmt.Sprintf("/proc/self/fdinfo/%d", id), syscall.O_RDONLY, 0)
-	if err != nil {
-		return -1, fmt.Errorf("could not find map id %d: %w", id, err)
+	fdC := C.bpf_map_get_fd_by_id(C.uint(id))
+	if fdC < 0 {
+		return -1, newError("GetMapFDByID", syscall.Errno(-fdC))
 	}
-	return fd, nil
+	return int(fdC), nil
 }
 
+// GetMapInfoByFD returns the BPFMapInfo for the map referenced by fd, retrieved
+// via the BPF_OBJ_GET_INFO_BY_FD bpf(2) command.
 func GetMapInfoByFD(fd int) (*BPFMapInfo, error) {
 	if fd < 0 {
 		return nil, fmt.Errorf("invalid file descriptor: %d", fd)
 	}
 
-	info := &BPFMapInfo{}
-	// Implementation would need to use syscall to get map info
-	return info, nil
+	var cInfo C.struct_bpf_map_info
+	infoLen := C.uint(unsafe.Sizeof(cInfo))
+
+	retC := C.bpf_obj_get_info_by_fd(C.int(fd), unsafe.Pointer(&cInfo), &infoLen)
+	if retC < 0 {
+		return nil, newError("GetMapInfoByFD", syscall.Errno(-retC))
+	}
+
+	return &BPFMapInfo{
+		Type:                  MapType(cInfo._type),
+		ID:                    uint32(cInfo.id),
+		KeySize:               uint32(cInfo.key_size),
+		ValueSize:             uint32(cInfo.value_size),
+		MaxEntries:            uint32(cInfo.max_entries),
+		MapFlags:              uint32(cInfo.map_flags),
+		Name:                  C.GoString(&cInfo.name[0]),
+		IfIndex:               uint32(cInfo.ifindex),
+		BTFVmlinuxValueTypeID: uint32(cInfo.btf_vmlinux_value_type_id),
+		NetnsDev:              uint64(cInfo.netns_dev),
+		NetnsIno:              uint64(cInfo.netns_ino),
+		BTFID:                 uint32(cInfo.btf_id),
+		BTFKeyTypeID:          uint32(cInfo.btf_key_type_id),
+		BTFValueTypeID:        uint32(cInfo.btf_value_type_id),
+		MapExtra:              uint64(cInfo.map_extra),
+	}, nil
 }
 
 func CalcMapValueSize(valueSize int, mapType MapType) (int, error) {
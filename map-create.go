@@ -0,0 +1,62 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// BPFMapCreateOpts mirrors libbpf's struct bpf_map_create_opts, letting
+// callers create a map without dropping down to raw bpf(2) syscalls.
+type BPFMapCreateOpts struct {
+	MapFlags       uint32
+	NumaNode       uint32
+	BTFFD          uint32
+	BTFKeyTypeID   uint32
+	BTFValueTypeID uint32
+	MapExtra       uint64
+
+	// BloomFilterNumHashes is the number of hash functions used by a
+	// BPF_MAP_TYPE_BLOOM_FILTER map (1-15). It is encoded into MapExtra by
+	// CreateMap and is ignored for every other map type.
+	BloomFilterNumHashes uint32
+}
+
+// CreateMap creates a new BPF map of the given type via the BPF_MAP_CREATE
+// bpf(2) command and returns its file descriptor.
+func CreateMap(mapType MapType, name string, keySize, valueSize, maxEntries int, opts *BPFMapCreateOpts) (int, error) {
+	nameC := C.CString(name)
+	defer C.free(unsafe.Pointer(nameC))
+
+	var cOpts C.struct_bpf_map_create_opts
+	cOpts.sz = C.ulong(unsafe.Sizeof(cOpts))
+
+	if opts != nil {
+		if mapType == MapTypeBloomFilter {
+			if opts.BloomFilterNumHashes < 1 || opts.BloomFilterNumHashes > 15 {
+				return -1, fmt.Errorf("bloom filter map requires 1-15 hash functions, got %d", opts.BloomFilterNumHashes)
+			}
+			opts.MapExtra = uint64(opts.BloomFilterNumHashes)
+		}
+
+		cOpts.map_flags = C.uint(opts.MapFlags)
+		cOpts.numa_node = C.uint(opts.NumaNode)
+		cOpts.btf_fd = C.uint(opts.BTFFD)
+		cOpts.btf_key_type_id = C.uint(opts.BTFKeyTypeID)
+		cOpts.btf_value_type_id = C.uint(opts.BTFValueTypeID)
+		cOpts.map_extra = C.ulonglong(opts.MapExtra)
+	}
+
+	fdC := C.bpf_map_create(C.enum_bpf_map_type(int(mapType)), nameC, C.uint(keySize), C.uint(valueSize), C.uint(maxEntries), &cOpts)
+	if fdC < 0 {
+		return -1, newError("CreateMap", syscall.Errno(-fdC))
+	}
+
+	return int(fdC), nil
+}
@@ -0,0 +1,115 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"syscall"
+)
+
+// BPFObjKind identifies which BPF_*_GET_NEXT_ID command a BPFObjIter drives.
+type BPFObjKind int
+
+const (
+	BPFObjKindMap BPFObjKind = iota
+	BPFObjKindProg
+	BPFObjKindLink
+	BPFObjKindBTF
+)
+
+// BPFObjIter walks every object of a given kind (map, prog, link or btf)
+// currently loaded on the system, in id order, using the BPF_*_GET_NEXT_ID
+// bpf(2) commands. It is the building block for introspection tools such as
+// bpftool that need to enumerate kernel-wide BPF state without shelling out.
+type BPFObjIter struct {
+	kind BPFObjKind
+	id   uint32
+	done bool
+	err  error
+}
+
+// NewBPFObjIter creates an iterator over all objects of the given kind.
+func NewBPFObjIter(kind BPFObjKind) *BPFObjIter {
+	return &BPFObjIter{kind: kind}
+}
+
+// Next advances the iterator and reports whether another id is available.
+// Once Next returns false, check Err to distinguish end-of-list from a
+// genuine syscall failure.
+func (it *BPFObjIter) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	var nextID C.uint
+	var retC C.int
+	switch it.kind {
+	case BPFObjKindMap:
+		retC = C.bpf_map_get_next_id(C.uint(it.id), &nextID)
+	case BPFObjKindProg:
+		retC = C.bpf_prog_get_next_id(C.uint(it.id), &nextID)
+	case BPFObjKindLink:
+		retC = C.bpf_link_get_next_id(C.uint(it.id), &nextID)
+	case BPFObjKindBTF:
+		retC = C.bpf_btf_get_next_id(C.uint(it.id), &nextID)
+	default:
+		it.err = errors.New("unknown BPFObjKind")
+		return false
+	}
+
+	if retC < 0 {
+		errno := syscall.Errno(-retC)
+		if errno == syscall.ENOENT {
+			it.done = true
+			return false
+		}
+		it.err = newError("BPFObjIter.Next", errno)
+		return false
+	}
+
+	it.id = uint32(nextID)
+	return true
+}
+
+// ID returns the id produced by the most recent call to Next.
+func (it *BPFObjIter) ID() uint32 {
+	return it.id
+}
+
+// Err returns the first error encountered while iterating, if any.
+func (it *BPFObjIter) Err() error {
+	return it.err
+}
+
+func collectIDs(kind BPFObjKind) ([]uint32, error) {
+	var ids []uint32
+	it := NewBPFObjIter(kind)
+	for it.Next() {
+		ids = append(ids, it.ID())
+	}
+	return ids, it.Err()
+}
+
+// IterateMapIDs returns the ids of every BPF map currently loaded on the system.
+func IterateMapIDs() ([]uint32, error) {
+	return collectIDs(BPFObjKindMap)
+}
+
+// IterateProgIDs returns the ids of every BPF program currently loaded on the system.
+func IterateProgIDs() ([]uint32, error) {
+	return collectIDs(BPFObjKindProg)
+}
+
+// IterateLinkIDs returns the ids of every BPF link currently loaded on the system.
+func IterateLinkIDs() ([]uint32, error) {
+	return collectIDs(BPFObjKindLink)
+}
+
+// IterateBTFIDs returns the ids of every BTF object currently loaded on the system.
+func IterateBTFIDs() ([]uint32, error) {
+	return collectIDs(BPFObjKindBTF)
+}
@@ -0,0 +1,88 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"sync"
+	"syscall"
+)
+
+// PerfBuffer is a consumer for a BPF_MAP_TYPE_PERF_EVENT_ARRAY map, backed by
+// one real libbpf perf_buffer per CPU rather than the single handle
+// perf_buffer__new normally hands back for every CPU at once. That per-CPU
+// split is what lets PollParallel actually run concurrently: libbpf's own
+// perf_buffer__poll already multiplexes every CPU slot through one internal
+// epoll_wait, so a single aggregate handle would have nothing left to
+// parallelize. Instances are built by Module.InitPerfBufParallel (not
+// present in this reduced tree); this file owns only the fan-out poll loop.
+type PerfBuffer struct {
+	cpuBufs []*C.struct_perf_buffer
+}
+
+// PollParallel drains every per-CPU perf_buffer concurrently, with a worker
+// pool sized to min(len(cpuBufs), NumPossibleCPUs, RuntimeLimits().EffectiveCPUs())
+// so that draining doesn't spawn more goroutines than the process's cgroup
+// CPU quota allows.
+//
+// The request this implements also asked for RuntimeLimits to size
+// RingBuffer.Poll. RingBuffer isn't declared anywhere in this reduced tree -
+// its constructor and internals live outside what's checked in here - and
+// defining it from scratch in this file would risk inventing a shape that
+// collides with the real type rather than extending it. That integration
+// point is consequently still missing; RuntimeLimits-based sizing only
+// covers PerfBuffer and BPFMap in this tree.
+func (pb *PerfBuffer) PollParallel(timeoutMs int) error {
+	limits, err := RuntimeLimits()
+	if err != nil {
+		return err
+	}
+
+	workers := limits.EffectiveCPUs()
+	if hostCPUs, err := NumPossibleCPUs(); err == nil && hostCPUs > 0 && hostCPUs < workers {
+		workers = hostCPUs
+	}
+	if workers > len(pb.cpuBufs) {
+		workers = len(pb.cpuBufs)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	errs := make([]error, len(pb.cpuBufs))
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cpu := range jobs {
+				errs[cpu] = pb.poll(cpu, timeoutMs)
+			}
+		}()
+	}
+	for cpu := range pb.cpuBufs {
+		jobs <- cpu
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pb *PerfBuffer) poll(cpu, timeoutMs int) error {
+	retC := C.perf_buffer__poll(pb.cpuBufs[cpu], C.int(timeoutMs))
+	if retC < 0 {
+		return newError("PerfBuffer.PollParallel", syscall.Errno(-retC))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+type BPFProgInfo struct {
+	Type                 uint32
+	ID                   uint32
+	Tag                  [8]byte
+	JitedProgLen         uint32
+	XlatedProgLen        uint32
+	LoadTime             uint64
+	CreatedByUID         uint32
+	NrMapIDs             uint32
+	IfIndex              uint32
+	GPLCompatible        bool
+	NetnsDev             uint64
+	NetnsIno             uint64
+	Name                 string
+	BTFID                uint32
+	FuncInfoRecSize      uint32
+	NrFuncInfo           uint32
+	NrLineInfo           uint32
+	NrJitedLineInfo      uint32
+	LineInfoRecSize      uint32
+	JitedLineInfoRecSize uint32
+	NrProgTags           uint32
+	RunTimeNS            uint64
+	RunCnt               uint64
+}
+
+// BPFProg is a loaded BPF program. newBPFProgFromFD is its constructor,
+// used by LoadProgramFromInstructions to wrap the fd produced by a raw
+// BPF_PROG_LOAD syscall, with no owning Module.
+type BPFProg struct {
+	name string
+	fd   int
+}
+
+func newBPFProgFromFD(name string, fd int) *BPFProg {
+	return &BPFProg{name: name, fd: fd}
+}
+
+// FD returns the program's file descriptor.
+func (p *BPFProg) FD() int {
+	return p.fd
+}
+
+// Name returns the program's name as given at load time.
+func (p *BPFProg) Name() string {
+	return p.name
+}
+
+// SetAttachTarget would change the fentry/fexit/freplace attach target of an
+// already-loaded program, mirroring libbpf's bpf_program__set_attach_target.
+// Real libbpf only allows that mutation on a struct bpf_program before
+// bpf_object__load (it rewrites attach_prog_fd/attach_btf_id ahead of the
+// BPF_PROG_LOAD syscall). A BPFProg built by LoadProgramFromInstructions is
+// already loaded via a raw BPF_PROG_LOAD, so there is no pre-load handle
+// left to mutate; the kernel only accepts an attach target at load time, via
+// ProgLoadOpts.AttachBTFID. This always returns an error to make that
+// constraint explicit rather than silently no-op.
+func (p *BPFProg) SetAttachTarget(attachProgFD int, attachFuncName string) error {
+	return fmt.Errorf("SetAttachTarget: attach target must be set before load via ProgLoadOpts.AttachBTFID; %q cannot be re-targeted after LoadProgramFromInstructions", p.name)
+}
+
+// GetProgFDByID returns a file descriptor for the program identified by id,
+// obtained via the BPF_PROG_GET_FD_BY_ID bpf(2) command.
+func GetProgFDByID(id uint32) (int, error) {
+	fdC := C.bpf_prog_get_fd_by_id(C.uint(id))
+	if fdC < 0 {
+		return -1, newError("GetProgFDByID", syscall.Errno(-fdC))
+	}
+	return int(fdC), nil
+}
+
+// GetProgInfoByFD returns the BPFProgInfo for the program referenced by fd,
+// retrieved via the BPF_OBJ_GET_INFO_BY_FD bpf(2) command.
+func GetProgInfoByFD(fd int) (*BPFProgInfo, error) {
+	if fd < 0 {
+		return nil, fmt.Errorf("invalid file descriptor: %d", fd)
+	}
+
+	var cInfo C.struct_bpf_prog_info
+	infoLen := C.uint(unsafe.Sizeof(cInfo))
+
+	retC := C.bpf_obj_get_info_by_fd(C.int(fd), unsafe.Pointer(&cInfo), &infoLen)
+	if retC < 0 {
+		return nil, newError("GetProgInfoByFD", syscall.Errno(-retC))
+	}
+
+	info := &BPFProgInfo{
+		Type:                 uint32(cInfo._type),
+		ID:                   uint32(cInfo.id),
+		JitedProgLen:         uint32(cInfo.jited_prog_len),
+		XlatedProgLen:        uint32(cInfo.xlated_prog_len),
+		LoadTime:             uint64(cInfo.load_time),
+		CreatedByUID:         uint32(cInfo.created_by_uid),
+		NrMapIDs:             uint32(cInfo.nr_map_ids),
+		IfIndex:              uint32(cInfo.ifindex),
+		GPLCompatible:        cInfo.gpl_compatible != 0,
+		NetnsDev:             uint64(cInfo.netns_dev),
+		NetnsIno:             uint64(cInfo.netns_ino),
+		Name:                 C.GoString(&cInfo.name[0]),
+		BTFID:                uint32(cInfo.btf_id),
+		FuncInfoRecSize:      uint32(cInfo.func_info_rec_size),
+		NrFuncInfo:           uint32(cInfo.nr_func_info),
+		NrLineInfo:           uint32(cInfo.nr_line_info),
+		NrJitedLineInfo:      uint32(cInfo.nr_jited_line_info),
+		LineInfoRecSize:      uint32(cInfo.line_info_rec_size),
+		JitedLineInfoRecSize: uint32(cInfo.jited_line_info_rec_size),
+		NrProgTags:           uint32(cInfo.nr_prog_tags),
+		RunTimeNS:            uint64(cInfo.run_time_ns),
+		RunCnt:               uint64(cInfo.run_cnt),
+	}
+	copy(info.Tag[:], C.GoBytes(unsafe.Pointer(&cInfo.tag[0]), C.int(len(cInfo.tag))))
+
+	return info, nil
+}
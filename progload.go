@@ -0,0 +1,83 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/khulnasoft-lab/libbpfgo/asm"
+)
+
+// ProgLoadOpts mirrors libbpf's struct bpf_prog_load_opts for the subset of
+// fields relevant to loading a raw instruction stream.
+type ProgLoadOpts struct {
+	KernelVersion uint32
+	ProgFlags     uint32
+	LogLevel      uint32
+	AttachBTFID   uint32
+}
+
+// LoadProgramFromInstructions assembles insns and loads them as a BPF program
+// via the BPF_PROG_LOAD bpf(2) command, without requiring clang at runtime.
+// This lets callers load dynamically generated programs, such as filters
+// compiled from a DSL or JIT'd match rules.
+func LoadProgramFromInstructions(name string, progType BPFProgType, insns asm.Instructions, license string, opts *ProgLoadOpts) (*BPFProg, error) {
+	var buf bytes.Buffer
+	if err := insns.Marshal(&buf, binary.LittleEndian); err != nil {
+		return nil, fmt.Errorf("assembling instructions for program %s: %w", name, err)
+	}
+
+	code := buf.Bytes()
+	if len(code)%8 != 0 {
+		return nil, fmt.Errorf("assembled instruction stream for program %s is not 8-byte aligned", name)
+	}
+
+	nameC := C.CString(name)
+	defer C.free(unsafe.Pointer(nameC))
+
+	licenseC := C.CString(license)
+	defer C.free(unsafe.Pointer(licenseC))
+
+	const logBufSize = 64 * 1024
+	logBuf := make([]byte, logBufSize)
+
+	var cOpts C.struct_bpf_prog_load_opts
+	cOpts.sz = C.ulong(unsafe.Sizeof(cOpts))
+	cOpts.log_buf = (*C.char)(unsafe.Pointer(&logBuf[0]))
+	cOpts.log_size = C.uint(logBufSize)
+	if opts != nil {
+		cOpts.kern_version = C.uint(opts.KernelVersion)
+		cOpts.prog_flags = C.uint(opts.ProgFlags)
+		cOpts.log_level = C.uint(opts.LogLevel)
+		cOpts.attach_btf_id = C.uint(opts.AttachBTFID)
+	}
+	// logBuf is always allocated, so always ask the kernel to populate it;
+	// otherwise a nil opts (or an explicit LogLevel of 0) leaves newLoadError
+	// reporting an empty Log on failure.
+	if cOpts.log_level == 0 {
+		cOpts.log_level = 1
+	}
+
+	fdC := C.bpf_prog_load(
+		C.enum_bpf_prog_type(int(progType)),
+		nameC,
+		licenseC,
+		(*C.struct_bpf_insn)(unsafe.Pointer(&code[0])),
+		C.size_t(len(code)/8),
+		&cOpts,
+	)
+	if fdC < 0 {
+		log := string(bytes.TrimRight(logBuf, "\x00"))
+		return nil, newLoadError("LoadProgramFromInstructions", syscall.Errno(-fdC), log)
+	}
+
+	return newBPFProgFromFD(name, int(fdC)), nil
+}
@@ -5,18 +5,18 @@ import "C"
 import (
 	"encoding/binary"
 	"os"
-	"runtime"
 	"syscall"
 	"time"
 
 	"fmt"
 
 	bpf "github.com/khulnasoft-lab/libbpfgo"
+	"github.com/khulnasoft-lab/libbpfgo/btf"
 )
 
 // main sets up and attaches an eBPF program, then polls a ring buffer for events.
 // It creates a BPF module from "main.bpf.o", retrieves the "foobar" program, and sets its expected attach type to BPFAttachTypeTraceFentry.
-// The function configures the program’s attach target based on system architecture and verifies the auto-attach setting.
+// The function resolves the program's attach target from the kernel's own BTF and verifies the auto-attach setting.
 // After loading the BPF object and attaching the program, main initializes a ring buffer to collect events
 // and spawns a goroutine to generate events. It continuously polls for events, validating that each contains the expected value,
 // and exits on error or after receiving more than five valid events.
@@ -47,8 +47,12 @@ func main() {
 		os.Exit(-1)
 	}
 
-	funcName := fmt.Sprintf("__%s_sys_mmap", ksymArch())
-	err = prog.SetAttachTarget(0, funcName)
+	vmlinux, err := btf.LoadKernelSpec()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+	err = prog.SetAttachBTF(vmlinux, "sys_mmap")
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(-1)
@@ -104,14 +108,3 @@ recvLoop:
 	rb.Stop()
 	rb.Close()
 }
-
-func ksymArch() string {
-	switch runtime.GOARCH {
-	case "amd64":
-		return "x64"
-	case "arm64":
-		return "arm64"
-	default:
-		panic("unsupported architecture")
-	}
-}
@@ -0,0 +1,215 @@
+package libbpfgo
+
+/*
+#cgo LDFLAGS: -lelf -lz
+#include "libbpfgo.h"
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// userRingbufHdrSize is the size, in bytes, of the per-sample header
+	// libbpf's bpf_user_ringbuf_* helpers expect before the sample payload.
+	userRingbufHdrSize = 8
+
+	// userRingbufAlign is the alignment, in bytes, samples are rounded up to.
+	userRingbufAlign = 8
+
+	// userRingbufBusyBit marks a reserved-but-not-yet-submitted sample so the
+	// kernel consumer knows to stop draining until it is submitted or discarded.
+	userRingbufBusyBit = uint32(1) << 31
+
+	// userRingbufDiscardBit marks a sample as discarded; the kernel consumer
+	// skips it without handing it to the BPF program.
+	userRingbufDiscardBit = uint32(1) << 30
+)
+
+// BPFUserRingBuf is a producer handle for a BPF_MAP_TYPE_USER_RINGBUF map. It
+// lets userspace push samples that a BPF program later drains with the
+// bpf_user_ringbuf_drain() helper, the mirror image of Module.InitRingBuf.
+type BPFUserRingBuf struct {
+	mapFD int
+
+	mask         uint32 // data size - 1, data size is always a power of two
+	consumerPos  *uint64
+	producerPos  *uint64
+	data         []byte // the data pages, mapped twice back-to-back so a wrapping sample is contiguous
+	consumerMmap []byte
+	producerMmap []byte
+}
+
+// NewUserRingBuf creates a producer for the BPF_MAP_TYPE_USER_RINGBUF map
+// named mapName in module. The returned BPFUserRingBuf owns mmap'd memory and
+// must be closed with Close once the caller is done submitting samples.
+func (m *Module) NewUserRingBuf(mapName string) (*BPFUserRingBuf, error) {
+	bpfMap, err := m.GetMap(mapName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find map %s: %w", mapName, err)
+	}
+	if bpfMap.Type() != MapTypeUserRingbuf {
+		return nil, fmt.Errorf("map %s is not a BPF_MAP_TYPE_USER_RINGBUF map", mapName)
+	}
+
+	pageSize := syscall.Getpagesize()
+	mapFD := bpfMap.FD()
+
+	info, err := GetMapInfoByFD(mapFD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get info for map %s: %w", mapName, err)
+	}
+	dataSize := int(info.MaxEntries)
+
+	consumerMmap, err := syscall.Mmap(mapFD, 0, pageSize, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mmap consumer page for map %s: %w", mapName, err)
+	}
+
+	producerMmap, err := mmapDoubleMappedProducer(mapFD, pageSize, dataSize)
+	if err != nil {
+		syscall.Munmap(consumerMmap)
+		return nil, fmt.Errorf("failed to mmap producer region for map %s: %w", mapName, err)
+	}
+
+	return &BPFUserRingBuf{
+		mapFD:        mapFD,
+		mask:         uint32(dataSize) - 1,
+		consumerPos:  (*uint64)(unsafe.Pointer(&consumerMmap[0])),
+		producerPos:  (*uint64)(unsafe.Pointer(&producerMmap[0])),
+		data:         producerMmap[pageSize : pageSize+2*dataSize],
+		consumerMmap: consumerMmap,
+		producerMmap: producerMmap,
+	}, nil
+}
+
+// mmapDoubleMappedProducer maps a BPF_MAP_TYPE_USER_RINGBUF map's producer
+// page followed by its data pages, twice over, so that a sample whose
+// reservation straddles the end of the ring can be addressed as one
+// contiguous slice instead of needing explicit wrap-splitting logic on every
+// read/write. This mirrors libbpf's user_ring_buffer__new: a single mmap of
+// length pageSize+2*dataSize at file offset pageSize, which the kernel's BPF
+// map mmap file-operations handler recognizes and double-maps the data pages
+// for internally. Two separate mmap calls (e.g. a second one at file offset
+// 2*pageSize) are not a layout the kernel understands and fail with EINVAL.
+func mmapDoubleMappedProducer(mapFD, pageSize, dataSize int) ([]byte, error) {
+	totalSize := pageSize + 2*dataSize
+
+	data, err := syscall.Mmap(mapFD, int64(pageSize), totalSize, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mapping producer page and double-mapped data: %w", err)
+	}
+
+	return data, nil
+}
+
+// Reserve reserves size bytes in the ring buffer and returns a slice backed
+// by the mmap'd producer region for the caller to fill in. The reservation
+// must be finalized with Submit or Discard before the slice is reused, but
+// unlike the kernel's own producer, nothing here assumes only one reservation
+// is outstanding at a time: the sample slice returned is self-describing, so
+// two Reserve calls may be in flight together as long as each is finalized
+// with the slice Reserve gave it.
+func (rb *BPFUserRingBuf) Reserve(size uint32) ([]byte, error) {
+	total := roundUp(userRingbufHdrSize+size, userRingbufAlign)
+	if total > rb.mask+1 {
+		return nil, fmt.Errorf("sample of %d bytes is larger than the ring buffer", size)
+	}
+
+	for {
+		prod := atomic.LoadUint64(rb.producerPos)
+		cons := atomic.LoadUint64(rb.consumerPos)
+
+		if prod-cons+uint64(total) > uint64(rb.mask+1) {
+			return nil, fmt.Errorf("ring buffer is full")
+		}
+
+		// Claim the slot before writing its header: writing first and
+		// claiming second would let a losing, retrying racer overwrite a
+		// header some other goroutine already claimed the same speculative
+		// offset for.
+		if !atomic.CompareAndSwapUint64(rb.producerPos, prod, prod+uint64(total)) {
+			continue
+		}
+
+		offset := uint32(prod) & rb.mask
+		hdr := (*uint32)(unsafe.Pointer(&rb.data[offset]))
+		atomic.StoreUint32(hdr, total-userRingbufHdrSize|userRingbufBusyBit)
+
+		return rb.data[offset+userRingbufHdrSize : offset+total], nil
+	}
+}
+
+// Submit publishes a sample previously obtained from Reserve, making it
+// visible to the BPF program draining this ring buffer.
+func (rb *BPFUserRingBuf) Submit(sample []byte) {
+	rb.finalize(sample, 0)
+}
+
+// Discard abandons a sample previously obtained from Reserve without
+// publishing it.
+func (rb *BPFUserRingBuf) Discard(sample []byte) {
+	rb.finalize(sample, userRingbufDiscardBit)
+}
+
+// finalize locates sample's header from sample's own address rather than any
+// state stored on rb, so that interleaving two Reserve calls before either is
+// finalized can never finalize the wrong header.
+func (rb *BPFUserRingBuf) finalize(sample []byte, flag uint32) {
+	sampleAddr := uintptr(unsafe.Pointer(unsafe.SliceData(sample)))
+	dataAddr := uintptr(unsafe.Pointer(&rb.data[0]))
+	offset := uint32(sampleAddr-dataAddr) - userRingbufHdrSize
+
+	hdr := (*uint32)(unsafe.Pointer(&rb.data[offset]))
+	length := atomic.LoadUint32(hdr) &^ (userRingbufBusyBit | userRingbufDiscardBit)
+	atomic.StoreUint32(hdr, length|flag)
+}
+
+// SubmitBlocking reserves len(data) bytes, copies data in, submits the
+// sample, and if the ring buffer looks full wakes up a consumer blocked in
+// bpf_user_ringbuf_drain() via BPF_RINGBUF_FORCE_WAKEUP. It returns ctx.Err()
+// if the context is canceled before a reservation succeeds.
+func (rb *BPFUserRingBuf) SubmitBlocking(ctx context.Context, data []byte) error {
+	for {
+		sample, err := rb.Reserve(uint32(len(data)))
+		if err == nil {
+			copy(sample, data)
+			rb.Submit(sample)
+			return rb.forceWakeup()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+}
+
+func (rb *BPFUserRingBuf) forceWakeup() error {
+	retC := C.bpf_map_update_elem(C.int(rb.mapFD), nil, nil, C.ulonglong(C.BPF_RINGBUF_FORCE_WAKEUP))
+	if retC < 0 {
+		return newError("BPFUserRingBuf.SubmitBlocking", syscall.Errno(-retC))
+	}
+	return nil
+}
+
+// Close unmaps the producer and consumer regions backing this ring buffer.
+func (rb *BPFUserRingBuf) Close() error {
+	if err := syscall.Munmap(rb.producerMmap); err != nil {
+		return fmt.Errorf("failed to unmap producer region: %w", err)
+	}
+	if err := syscall.Munmap(rb.consumerMmap); err != nil {
+		return fmt.Errorf("failed to unmap consumer page: %w", err)
+	}
+	return nil
+}
+
+func roundUp(n, align uint32) uint32 {
+	return (n + align - 1) &^ (align - 1)
+}